@@ -3,22 +3,32 @@ package adk
 import (
 	"bufio"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"strings"
 
+	"github.com/KennethanCeyer/adk-go/agents"
 	"github.com/KennethanCeyer/adk-go/agents/interfaces"
+	"github.com/KennethanCeyer/adk-go/agents/invocation"
 	modelstypes "github.com/KennethanCeyer/adk-go/models/types"
 	"github.com/KennethanCeyer/adk-go/sessions"
+	"github.com/google/uuid"
 )
 
+const maxHistoryTurns = 10
+
 type SimpleCLIRunner struct {
-	AgentToRun interfaces.LlmAgent
+	AgentToRun interfaces.Agent
 	Session    *sessions.Session
+	RunConfig  *invocation.RunConfig // Limits each turn; nil means no limits
+
+	scanner *bufio.Scanner
 }
 
-func NewSimpleCLIRunner(agent interfaces.LlmAgent, sess *sessions.Session) (*SimpleCLIRunner, error) {
+func NewSimpleCLIRunner(agent interfaces.Agent, sess *sessions.Session) (*SimpleCLIRunner, error) {
 	if agent == nil {
 		return nil, fmt.Errorf("agent cannot be nil")
 	}
@@ -32,6 +42,9 @@ func (r *SimpleCLIRunner) Start(ctx context.Context) {
 	r.printAgentInfo()
 
 	scanner := bufio.NewScanner(os.Stdin)
+	r.scanner = scanner
+	ctx = invocation.WithConfirmer(ctx, r.confirmTool)
+	ctx = invocation.WithRunConfig(ctx, r.RunConfig)
 
 	for {
 		select {
@@ -66,45 +79,150 @@ func (r *SimpleCLIRunner) Start(ctx context.Context) {
 
 		userMessage := modelstypes.Message{Role: "user", Parts: []modelstypes.Part{{Text: &userInputText}}}
 
-		agentResponse, err := r.AgentToRun.Process(ctx, r.Session.History, userMessage)
+		agentResponse, err := RunTurn(ctx, r.AgentToRun, r.Session, userMessage)
 		if err != nil {
 			if ctx.Err() != nil {
 				log.Printf("Agent Process call failed due to context cancellation: %v", err)
 				return
 			}
-			fmt.Printf("[%s-error]: I encountered an issue: %v\n", r.AgentToRun.GetName(), err)
-			r.Session.History = append(r.Session.History, userMessage)
-			sessions.Save(r.Session)
+			fmt.Printf("[%s-error]: I encountered an issue: %v\n", ActiveAgent(r.AgentToRun, r.Session).GetName(), err)
 			continue
 		}
 
-		r.Session.History = append(r.Session.History, userMessage)
-		if agentResponse != nil {
-			r.Session.History = append(r.Session.History, *agentResponse)
-		}
-
+		respondingAgent := ActiveAgent(r.AgentToRun, r.Session).GetName()
 		if agentResponse != nil && len(agentResponse.Parts) > 0 {
-			var responseTexts []string
 			for _, part := range agentResponse.Parts {
-				if part.Text != nil {
-					responseTexts = append(responseTexts, *part.Text)
-				}
 				if part.FunctionCall != nil {
 					log.Printf("Runner: Agent response unexpectedly contained FunctionCall: Name=%s.", part.FunctionCall.Name)
 				}
 			}
-			fmt.Printf("[%s]: %s\n", r.AgentToRun.GetName(), strings.Join(responseTexts, "\n"))
+			fmt.Printf("[%s]: %s\n", respondingAgent, ResponseText(agentResponse))
 		} else {
-			fmt.Printf("[%s]: (Agent returned no displayable content)\n", r.AgentToRun.GetName())
+			fmt.Printf("[%s]: (Agent returned no displayable content)\n", respondingAgent)
 		}
+	}
+}
+
+// ActiveAgent returns the agent that owns the session: the agent below root
+// that the conversation was last transferred to, or root itself.
+func ActiveAgent(root interfaces.Agent, sess *sessions.Session) interfaces.Agent {
+	if sess.ActiveAgent == "" {
+		return root
+	}
+	if active := agents.FindAgent(root, sess.ActiveAgent); active != nil {
+		return active
+	}
+	return root
+}
+
+// RunTurn sends a single user message to the session's active agent below
+// root. The exchange is appended to the session history, which is then pruned
+// and saved. If the agent transfers the conversation, the receiving agent
+// becomes the active agent for the following turns. The turn is limited by
+// the RunConfig set on ctx with invocation.WithRunConfig, if any. Turns on
+// the same session run one at a time.
+func RunTurn(ctx context.Context, root interfaces.Agent, sess *sessions.Session, userMessage modelstypes.Message) (*modelstypes.Message, error) {
+	defer sess.BeginTurn()()
+	agent := ActiveAgent(root, sess)
+	invCtx := &invocation.InvocationContext{
+		ID:        uuid.NewString(),
+		Agent:     agent,
+		Session:   sess,
+		RunConfig: invocation.GetRunConfig(ctx),
+	}
+	ctx, cancel := invocation.Start(ctx, invCtx)
+	defer cancel()
+	agentResponse, err := agent.Process(ctx, sess.History, userMessage)
+	err = invCtx.BudgetErr(err, agent.GetName())
+	if transferredTo := invCtx.TransferredTo(); transferredTo != "" {
+		sess.ActiveAgent = transferredTo
+	}
+	if err != nil {
+		sess.History = append(sess.History, userMessage)
+		sessions.Save(sess)
+		return nil, err
+	}
+
+	sess.History = append(sess.History, userMessage)
+	if agentResponse != nil {
+		sess.History = append(sess.History, *agentResponse)
+	}
+
+	if len(sess.History) > maxHistoryTurns*2 {
+		sess.History = sess.History[len(sess.History)-(maxHistoryTurns*2):]
+	}
+
+	sessions.Save(sess)
+	return agentResponse, nil
+}
 
-		const maxHistoryTurns = 10
-		if len(r.Session.History) > maxHistoryTurns*2 {
-			r.Session.History = r.Session.History[len(r.Session.History)-(maxHistoryTurns*2):]
+// ResponseText joins the text parts of an agent response.
+func ResponseText(msg *modelstypes.Message) string {
+	if msg == nil {
+		return ""
+	}
+	var responseTexts []string
+	for _, part := range msg.Parts {
+		if part.Text != nil {
+			responseTexts = append(responseTexts, *part.Text)
+		}
+	}
+	return strings.Join(responseTexts, "\n")
+}
+
+// confirmTool prompts on the terminal for approval of a tool call. The user
+// can approve it, reject it with an optional reason, or replace its arguments
+// with a JSON object before approving.
+func (r *SimpleCLIRunner) confirmTool(ctx context.Context, req invocation.ToolConfirmationRequest) (invocation.ToolConfirmation, error) {
+	argsJSON, err := json.MarshalIndent(req.Args, "", "  ")
+	if err != nil {
+		argsJSON = []byte(fmt.Sprintf("%v", req.Args))
+	}
+	fmt.Printf("[%s] wants to run tool '%s' with args:\n%s\n", req.AgentName, req.ToolName, argsJSON)
+
+	for {
+		answer, err := r.prompt(ctx, "Approve? [y]es / [n]o / [e]dit args: ")
+		if err != nil {
+			return invocation.ToolConfirmation{}, err
+		}
+		switch strings.ToLower(answer) {
+		case "y", "yes":
+			return invocation.ToolConfirmation{Approved: true}, nil
+		case "n", "no":
+			reason, err := r.prompt(ctx, "Reason (optional): ")
+			if err != nil {
+				return invocation.ToolConfirmation{}, err
+			}
+			return invocation.ToolConfirmation{Approved: false, Reason: reason}, nil
+		case "e", "edit":
+			line, err := r.prompt(ctx, "New args as a JSON object: ")
+			if err != nil {
+				return invocation.ToolConfirmation{}, err
+			}
+			var args map[string]any
+			if err := json.Unmarshal([]byte(line), &args); err != nil || args == nil {
+				fmt.Println("Invalid JSON object, please try again.")
+				continue
+			}
+			return invocation.ToolConfirmation{Approved: true, Args: args}, nil
+		default:
+			fmt.Println("Please answer y, n or e.")
 		}
+	}
+}
 
-		sessions.Save(r.Session)
+func (r *SimpleCLIRunner) prompt(ctx context.Context, label string) (string, error) {
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+	fmt.Print(label)
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.ErrUnexpectedEOF
 	}
+	return strings.TrimSpace(r.scanner.Text()), nil
 }
 
 func (r *SimpleCLIRunner) printAgentInfo() {
@@ -112,15 +230,16 @@ func (r *SimpleCLIRunner) printAgentInfo() {
 	if desc := r.AgentToRun.GetDescription(); desc != "" {
 		fmt.Printf("Description: %s\n", desc)
 	}
-	if model := r.AgentToRun.GetModelIdentifier(); strings.HasPrefix(model, "workflow-") {
-		fmt.Println("Model: (This is a workflow agent)")
+	llmAgent, ok := r.AgentToRun.(interfaces.LlmAgent)
+	if !ok {
+		fmt.Printf("Type: %s\n", agents.TypeName(r.AgentToRun))
 	} else {
-		fmt.Printf("Model: %s\n", model)
-	}
-	if tools := r.AgentToRun.GetTools(); len(tools) > 0 {
-		fmt.Println("Available Tools:")
-		for _, tool := range tools {
-			fmt.Printf("  - %s: %s\n", tool.Name(), tool.Description())
+		fmt.Printf("Model: %s\n", llmAgent.GetModelIdentifier())
+		if tools := llmAgent.GetTools(); len(tools) > 0 {
+			fmt.Println("Available Tools:")
+			for _, tool := range tools {
+				fmt.Printf("  - %s: %s\n", tool.Name(), tool.Description())
+			}
 		}
 	}
 	fmt.Println("------------------------------------")
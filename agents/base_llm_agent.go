@@ -4,16 +4,21 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
+	"sort"
 	"sync"
 
 	"github.com/KennethanCeyer/adk-go/agents/callbacks"
 	"github.com/KennethanCeyer/adk-go/agents/invocation"
 
 	"github.com/KennethanCeyer/adk-go/agents/interfaces"
+	"github.com/KennethanCeyer/adk-go/codeexecutors"
 	"github.com/KennethanCeyer/adk-go/llmproviders"
 	"github.com/KennethanCeyer/adk-go/models"
 	modelstypes "github.com/KennethanCeyer/adk-go/models/types"
 	"github.com/KennethanCeyer/adk-go/tools"
+	"github.com/KennethanCeyer/adk-go/tools/artifacts"
+	"github.com/google/uuid"
 )
 
 type BaseLlmAgent struct {
@@ -31,6 +36,50 @@ type BaseLlmAgent struct {
 	AfterModelCallback   callbacks.AfterModelCallback
 	BeforeToolCallback   callbacks.BeforeToolCallback
 	AfterToolCallback    callbacks.AfterToolCallback
+
+	// Toolsets provide additional tools that are resolved on every model
+	// request, alongside the static tools given to NewBaseLlmAgent.
+	Toolsets []tools.Toolset
+
+	// ToolResultPolicy, when set, limits the size of tool results passed to
+	// the model.
+	ToolResultPolicy *ToolResultPolicy
+
+	// CodeExecutor, when set, runs the code the model marks for execution and
+	// feeds the results back to it before the turn ends. The model is told to
+	// tag such code with codeexecutors.ExecuteTag; other code blocks in its
+	// answers are not run.
+	CodeExecutor codeexecutors.CodeExecutor
+
+	// DisallowTransferToParent and DisallowTransferToPeers restrict which
+	// agents the model may transfer the conversation to. Sub-agents are always
+	// eligible.
+	DisallowTransferToParent bool
+	DisallowTransferToPeers  bool
+
+	// OutputKey, when set, stores the agent's final answer in the session
+	// state under this key, so that later agents can reference it as {key}
+	// in their instructions.
+	OutputKey string
+
+	// InstructionProvider, when set, builds the system instruction before
+	// every model call and replaces the static instruction.
+	InstructionProvider InstructionProvider
+
+	// GenerationConfig, when set, tunes the model's sampling for every call
+	// the agent makes.
+	GenerationConfig *modelstypes.GenerationConfig
+
+	// Planner, when set, shapes the instruction and the tools of every model
+	// call, for example to make the agent plan before it acts.
+	Planner Planner
+
+	subAgents []interfaces.Agent
+	parent    *BaseLlmAgent
+
+	// duplicateTools names the tools given to NewBaseLlmAgent more than
+	// once; only the last of them is kept. Reported by Validate.
+	duplicateTools []string
 }
 
 func NewBaseLlmAgent(
@@ -42,8 +91,12 @@ func NewBaseLlmAgent(
 	agentTools []tools.Tool,
 ) interfaces.LlmAgent {
 	toolMap := make(map[string]tools.Tool)
+	var duplicates []string
 	for _, t := range agentTools {
 		if t != nil {
+			if _, exists := toolMap[t.Name()]; exists && !slices.Contains(duplicates, t.Name()) {
+				duplicates = append(duplicates, t.Name())
+			}
 			toolMap[t.Name()] = t
 		}
 	}
@@ -54,6 +107,7 @@ func NewBaseLlmAgent(
 		systemInstruction: systemInstruction,
 		llmProvider:       provider,
 		tools:             toolMap,
+		duplicateTools:    duplicates,
 	}
 }
 
@@ -65,14 +119,36 @@ func (a *BaseLlmAgent) GetModelIdentifier() string { return a.modelIdentifier }
 
 func (a *BaseLlmAgent) GetSystemInstruction() *modelstypes.Message { return a.systemInstruction }
 
+// GetTools returns the static tools of the agent sorted by name. Tools from
+// Toolsets depend on the invocation and are only available via ResolveTools.
 func (a *BaseLlmAgent) GetTools() []tools.Tool {
 	toolSlice := make([]tools.Tool, 0, len(a.tools))
 	for _, t := range a.tools {
 		toolSlice = append(toolSlice, t)
 	}
+	sort.Slice(toolSlice, func(i, j int) bool { return toolSlice[i].Name() < toolSlice[j].Name() })
 	return toolSlice
 }
 
+// ResolveTools returns the static tools together with the tools the agent's
+// toolsets provide for ctx, sorted by name.
+func (a *BaseLlmAgent) ResolveTools(ctx context.Context) ([]tools.Tool, error) {
+	static := a.GetTools()
+	// Results are only offloaded when there is a session to store them in.
+	if a.ToolResultPolicy != nil && a.ToolResultPolicy.OffloadToArtifact && invocation.FromContext(ctx).Session != nil {
+		readArtifact := artifacts.NewReadArtifactTool(a.ToolResultPolicy.MaxBytes)
+		if _, exists := a.tools[readArtifact.Name()]; !exists {
+			static = append(static, readArtifact)
+		}
+	}
+	if targets := a.transferTargets(); len(targets) > 0 {
+		if _, exists := a.tools[transferToolName]; !exists {
+			static = append(static, &transferToAgentTool{targets: targets})
+		}
+	}
+	return tools.Resolve(ctx, static, a.Toolsets)
+}
+
 func (a *BaseLlmAgent) GetLLMProvider() llmproviders.LLMProvider { return a.llmProvider }
 
 func (a *BaseLlmAgent) Process(
@@ -87,7 +163,7 @@ func (a *BaseLlmAgent) Process(
 	callbackCtx := &callbacks.CallbackContext{
 		AgentName: a.GetName(),
 		InvocationID: invocation.FromContext(ctx).ID,
-		SessionState: nil, // TODO: Implement session state access
+		SessionState: invocation.FromContext(ctx).Session,
 		UserContent:  &latestMessage,
 	}
 
@@ -102,14 +178,43 @@ func (a *BaseLlmAgent) Process(
 
 	currentMessage := latestMessage
 
-	const maxToolCalls = 10
-	for i := 0; i < maxToolCalls; i++ {
+	invCtx := invocation.FromContext(ctx)
+	maxSteps := invCtx.MaxStepsPerAgent()
+	for i := 0; i < maxSteps; i++ {
+		turnTools, err := a.ResolveTools(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("agent '%s' failed to resolve tools: %w", a.name, err)
+		}
+
+		systemInstruction, err := a.instruction(ctx, &latestMessage)
+		if err != nil {
+			return nil, err
+		}
+
+		if a.CodeExecutor != nil {
+			systemInstruction = appendInstruction(systemInstruction, codeexecutors.Instruction(a.CodeExecutor.Languages()))
+		}
+		if a.Planner != nil {
+			rc := newReadonlyContext(ctx, a.name, &latestMessage)
+			planning, err := a.Planner.Instruction(rc)
+			if err != nil {
+				return nil, fmt.Errorf("agent '%s' planner failed: %w", a.name, err)
+			}
+			systemInstruction = appendInstruction(systemInstruction, planning)
+			turnTools = a.Planner.Tools(rc, turnTools)
+		}
+		toolMap := make(map[string]tools.Tool, len(turnTools))
+		for _, t := range turnTools {
+			toolMap[t.Name()] = t
+		}
+
 		llmReq := &models.LlmRequest{
 			ModelIdentifier:   a.modelIdentifier,
-			SystemInstruction: a.systemInstruction,
-			Tools:             a.GetTools(),
+			SystemInstruction: systemInstruction,
+			Tools:             turnTools,
 			History:           turnHistory,
 			LatestMessage:     currentMessage,
+			GenerationConfig:  a.GenerationConfig,
 		}
 
 		var llmResponse *models.LlmResponse
@@ -120,8 +225,12 @@ func (a *BaseLlmAgent) Process(
 		if llmResponse != nil {
 			invocation.SendInternalLog(ctx, "Agent '%s' model call was overridden by a callback.", a.name)
 		} else {
+			if err := invCtx.BeginLLMCall(a.name); err != nil {
+				invocation.SendInternalLog(ctx, "Agent '%s' stopped: %v", a.name, err)
+				return nil, err
+			}
 			llmResponseMsg, err := a.llmProvider.GenerateContent(
-				ctx,
+				llmproviders.WithGenerationConfig(ctx, llmReq.GenerationConfig),
 				llmReq.ModelIdentifier,
 				llmReq.SystemInstruction,
 				llmReq.Tools,
@@ -129,9 +238,15 @@ func (a *BaseLlmAgent) Process(
 				llmReq.LatestMessage,
 			)
 			if err != nil {
-				return nil, fmt.Errorf("LLM interaction failed: %w", err)
+				return nil, invCtx.BudgetErr(fmt.Errorf("LLM interaction failed: %w", err), a.name)
 			}
 			llmResponse = &models.LlmResponse{Content: llmResponseMsg}
+			if llmResponseMsg != nil {
+				if err := invCtx.RecordUsage(a.name, a.modelIdentifier, llmResponseMsg.UsageMetadata); err != nil {
+					invocation.SendInternalLog(ctx, "Agent '%s' stopped: %v", a.name, err)
+					return nil, err
+				}
+			}
 		}
 
 		if a.AfterModelCallback != nil {
@@ -154,21 +269,48 @@ func (a *BaseLlmAgent) Process(
 			}
 		}
 
-		if len(functionCalls) == 0 {
+		var codeBlocks []modelstypes.ExecutableCode
+		if a.CodeExecutor != nil {
+			codeBlocks = pendingCodeBlocks(llmResponse.Content, a.CodeExecutor.Languages())
+		}
+
+		if len(functionCalls) == 0 && len(codeBlocks) == 0 {
 			if a.AfterAgentCallback != nil {
 				if finalResponse := a.AfterAgentCallback(callbackCtx, llmResponse.Content); finalResponse != nil {
+					a.saveOutput(ctx, finalResponse)
 					return finalResponse, nil
 				}
 			}
+			a.saveOutput(ctx, llmResponse.Content)
 			return llmResponse.Content, nil
 		}
 
+		approvedCalls, declinedParts, err := a.confirmToolCalls(ctx, toolMap, functionCalls)
+		if err != nil {
+			return nil, err
+		}
+
+		// The tool budget is reserved in call order before any call starts, so
+		// which calls fit does not depend on goroutine scheduling. If the batch
+		// does not fit, none of it runs and the turn stops.
+		for _, fc := range approvedCalls {
+			if _, found := toolMap[fc.Name]; !found {
+				continue
+			}
+			if err := invCtx.BeginToolCall(a.name); err != nil {
+				invocation.SendInternalLog(ctx, "Agent '%s' stopped: %v", a.name, err)
+				return nil, err
+			}
+		}
+
 		var wg sync.WaitGroup
-		toolResponseParts := make(chan modelstypes.Part, len(functionCalls))
+		toolResponseParts := make(chan modelstypes.Part, len(approvedCalls))
 
-		invocation.SendInternalLog(ctx, "Agent '%s' is calling %d tools in parallel...", a.name, len(functionCalls))
+		if len(approvedCalls) > 0 {
+			invocation.SendInternalLog(ctx, "Agent '%s' is calling %d tools in parallel...", a.name, len(approvedCalls))
+		}
 
-		for _, fc := range functionCalls {
+		for _, fc := range approvedCalls {
 			wg.Add(1)
 			go func(call *modelstypes.FunctionCall) {
 				defer wg.Done()
@@ -182,7 +324,7 @@ func (a *BaseLlmAgent) Process(
 					}
 				}
 				invocation.SendInternalLog(ctx, "  - Calling tool '%s'%s", call.Name, argsStr)
-				toolToExecute, found := a.tools[call.Name]
+				toolToExecute, found := toolMap[call.Name]
 				var responsePart modelstypes.Part
 
 				if !found {
@@ -202,10 +344,10 @@ func (a *BaseLlmAgent) Process(
 						invocation.SendInternalLog(ctx, "  - Error: %s", errText)
 						responsePart = modelstypes.Part{FunctionResponse: &modelstypes.FunctionResponse{Name: call.Name, Response: map[string]any{"error": errText}}}
 					} else {
-						// The result from a tool must be a map[string]any to be used in the FunctionResponse.
-						toolResultMap, ok := toolResult.(map[string]any)
-						if !ok {
-							errText := fmt.Sprintf("tool '%s' result is not a map[string]any, but %T", toolToExecute.Name(), toolResult)
+						// Results of any JSON-serializable type are normalized into the map a FunctionResponse carries.
+						toolResultMap, err := tools.NormalizeResult(toolResult)
+						if err != nil {
+							errText := fmt.Sprintf("tool '%s' returned an invalid result: %v", toolToExecute.Name(), err)
 							invocation.SendInternalLog(ctx, "  - Error: %s", errText)
 							responsePart = modelstypes.Part{FunctionResponse: &modelstypes.FunctionResponse{Name: call.Name, Response: map[string]any{"error": errText}}}
 						} else {
@@ -215,6 +357,10 @@ func (a *BaseLlmAgent) Process(
 								}
 							}
 							invocation.SendInternalLog(ctx, "  - Tool '%s' executed successfully", toolToExecute.Name())
+							// Pages of offloaded results are already sized by read_artifact.
+							if _, isReadArtifact := toolToExecute.(*artifacts.ReadArtifactTool); !isReadArtifact {
+								toolResultMap = a.ToolResultPolicy.apply(ctx, toolToExecute.Name(), toolResultMap)
+							}
 							responsePart = modelstypes.Part{FunctionResponse: &modelstypes.FunctionResponse{Name: call.Name, Response: toolResultMap}}
 						}
 					}
@@ -231,7 +377,18 @@ func (a *BaseLlmAgent) Process(
 			collectedParts = append(collectedParts, part)
 		}
 
-		toolResponseMessage := modelstypes.Message{Role: "function", Parts: collectedParts}
+		if target := invocation.FromContext(ctx).TakeTransfer(); target != "" {
+			return a.transfer(ctx, target, history, latestMessage)
+		}
+
+		collectedParts = append(collectedParts, declinedParts...)
+		collectedParts = append(collectedParts, a.executeCode(ctx, codeBlocks)...)
+
+		role := "function"
+		if len(functionCalls) == 0 {
+			role = "user"
+		}
+		toolResponseMessage := modelstypes.Message{Role: role, Parts: collectedParts}
 
 		currentMessage = toolResponseMessage
 	}
@@ -241,5 +398,100 @@ func (a *BaseLlmAgent) Process(
 			return finalResponse, nil
 		}
 	}
-	return nil, fmt.Errorf("exceeded maximum tool calls (%d) in a single turn", maxToolCalls)
+	return nil, &invocation.BudgetExceededError{Budget: invocation.BudgetAgentSteps, Agent: a.name, Limit: float64(maxSteps), Used: float64(maxSteps)}
+}
+
+// confirmToolCalls asks the user, one call at a time, to approve the calls
+// whose tool requires confirmation. Declined calls are answered with a
+// FunctionResponse telling the model that the user said no.
+func (a *BaseLlmAgent) confirmToolCalls(ctx context.Context, toolMap map[string]tools.Tool, calls []*modelstypes.FunctionCall) ([]*modelstypes.FunctionCall, []modelstypes.Part, error) {
+	var approved []*modelstypes.FunctionCall
+	var declined []modelstypes.Part
+	for _, call := range calls {
+		tool, ok := toolMap[call.Name].(tools.ConfirmableTool)
+		if !ok || !tool.RequiresConfirmation(call.Args) {
+			approved = append(approved, call)
+			continue
+		}
+
+		req := invocation.ToolConfirmationRequest{
+			ID:        uuid.NewString(),
+			AgentName: a.name,
+			ToolName:  call.Name,
+			Args:      call.Args,
+		}
+		if sender, ok := invocation.GetUISender(ctx); ok {
+			sender(invocation.ToolConfirmationRequestEvent, req)
+		}
+		invocation.SendInternalLog(ctx, "  - Waiting for the user to confirm tool '%s'", call.Name)
+
+		confirmation := invocation.ToolConfirmation{Reason: "no user is available to confirm this call"}
+		if confirmer, ok := invocation.GetConfirmer(ctx); ok {
+			var err error
+			confirmation, err = confirmer(ctx, req)
+			if err != nil {
+				return nil, nil, fmt.Errorf("confirmation of tool '%s' failed: %w", call.Name, err)
+			}
+		}
+		if !confirmation.Approved {
+			errText := fmt.Sprintf("the user declined to run tool '%s'", call.Name)
+			if confirmation.Reason != "" {
+				errText += ": " + confirmation.Reason
+			}
+			invocation.SendInternalLog(ctx, "  - Tool '%s' was declined", call.Name)
+			declined = append(declined, modelstypes.Part{FunctionResponse: &modelstypes.FunctionResponse{Name: call.Name, Response: map[string]any{"error": errText}}})
+			continue
+		}
+		if confirmation.Args != nil {
+			call.Args = confirmation.Args
+		}
+		invocation.SendInternalLog(ctx, "  - Tool '%s' was approved", call.Name)
+		approved = append(approved, call)
+	}
+	return approved, declined, nil
+}
+
+// pendingCodeBlocks collects the code the model wants executed: executable
+// code parts that are not already followed by a result, and fenced blocks in
+// text tagged with codeexecutors.ExecuteTag for one of the executor's
+// languages. Other fenced blocks are left alone, so an answer that only shows
+// a snippet is still final.
+func pendingCodeBlocks(msg *modelstypes.Message, languages []string) []modelstypes.ExecutableCode {
+	var blocks []modelstypes.ExecutableCode
+	for i, part := range msg.Parts {
+		switch {
+		case part.ExecutableCode != nil:
+			if i+1 < len(msg.Parts) && msg.Parts[i+1].CodeExecutionResult != nil {
+				continue // Already executed by the model provider.
+			}
+			code := *part.ExecutableCode
+			code.Language = codeexecutors.NormalizeLanguage(code.Language)
+			if slices.Contains(languages, code.Language) {
+				blocks = append(blocks, code)
+			}
+		case part.Text != nil:
+			blocks = append(blocks, codeexecutors.ExtractCodeBlocks(*part.Text, languages)...)
+		}
+	}
+	return blocks
+}
+
+func (a *BaseLlmAgent) executeCode(ctx context.Context, blocks []modelstypes.ExecutableCode) []modelstypes.Part {
+	if len(blocks) == 0 {
+		return nil
+	}
+	invocation.SendInternalLog(ctx, "Agent '%s' is executing %d code blocks...", a.name, len(blocks))
+	parts := make([]modelstypes.Part, 0, len(blocks))
+	for _, block := range blocks {
+		invocation.SendInternalLog(ctx, "  - Executing %s code (%d bytes)", block.Language, len(block.Code))
+		result, err := a.CodeExecutor.Execute(ctx, block)
+		if err != nil {
+			invocation.SendInternalLog(ctx, "  - Error: %v", err)
+			result = &modelstypes.CodeExecutionResult{Outcome: modelstypes.OutcomeFailed, Output: err.Error()}
+		} else {
+			invocation.SendInternalLog(ctx, "  - Code execution finished with %s", result.Outcome)
+		}
+		parts = append(parts, modelstypes.Part{CodeExecutionResult: result})
+	}
+	return parts
 }
@@ -0,0 +1,43 @@
+package agents
+
+import (
+	"context"
+	"errors"
+
+	"github.com/KennethanCeyer/adk-go/agents/invocation"
+	"github.com/KennethanCeyer/adk-go/llmproviders"
+	modelstypes "github.com/KennethanCeyer/adk-go/models/types"
+)
+
+// generate makes a single model call on behalf of agentName, such as a
+// classification or a synthesis, within the budgets of the invocation.
+func generate(
+	ctx context.Context,
+	agentName string,
+	provider llmproviders.LLMProvider,
+	modelID string,
+	systemInstruction *modelstypes.Message,
+	prompt modelstypes.Message,
+) (*modelstypes.Message, error) {
+	invCtx := invocation.FromContext(ctx)
+	if err := invCtx.BeginLLMCall(agentName); err != nil {
+		return nil, err
+	}
+	response, err := provider.GenerateContent(ctx, modelID, systemInstruction, nil, nil, prompt)
+	if err != nil {
+		return nil, invCtx.BudgetErr(err, agentName)
+	}
+	if response != nil {
+		if err := invCtx.RecordUsage(agentName, modelID, response.UsageMetadata); err != nil {
+			return nil, err
+		}
+	}
+	return response, nil
+}
+
+// budgetExceeded reports whether err comes from an exhausted budget. Such
+// errors end the invocation even where workflow agents tolerate failures.
+func budgetExceeded(err error) bool {
+	var exceeded *invocation.BudgetExceededError
+	return errors.As(err, &exceeded)
+}
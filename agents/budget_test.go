@@ -0,0 +1,85 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/KennethanCeyer/adk-go/agents/invocation"
+	modelstypes "github.com/KennethanCeyer/adk-go/models/types"
+	"github.com/KennethanCeyer/adk-go/tools"
+)
+
+type countingTool struct{ calls atomic.Int32 }
+
+func (t *countingTool) Name() string        { return "lookup" }
+func (t *countingTool) Description() string { return "Looks something up." }
+func (t *countingTool) Parameters() any     { return map[string]any{"type": "object"} }
+func (t *countingTool) Execute(context.Context, any) (any, error) {
+	t.calls.Add(1)
+	return map[string]any{"ok": true}, nil
+}
+
+// batchProvider asks for three tool calls at once, then answers with text and
+// keeps the tool responses it was given.
+type batchProvider struct {
+	responses []modelstypes.Part
+}
+
+func (p *batchProvider) GenerateContent(_ context.Context, _ string, _ *modelstypes.Message, _ []tools.Tool, _ []modelstypes.Message, latest modelstypes.Message) (*modelstypes.Message, error) {
+	if latest.Role == "user" {
+		call := modelstypes.Part{FunctionCall: &modelstypes.FunctionCall{Name: "lookup", Args: map[string]any{}}}
+		return &modelstypes.Message{Role: "model", Parts: []modelstypes.Part{call, call, call}}, nil
+	}
+	p.responses = latest.Parts
+	text := "answer"
+	return &modelstypes.Message{Role: "model", Parts: []modelstypes.Part{{Text: &text}}}, nil
+}
+
+func TestToolCallsPastBudgetStopTheTurn(t *testing.T) {
+	tool := &countingTool{}
+	provider := &batchProvider{}
+	agent := NewBaseLlmAgent("agent", "", "model", nil, provider, []tools.Tool{tool})
+
+	invCtx := &invocation.InvocationContext{RunConfig: &invocation.RunConfig{MaxToolCalls: 2}}
+	ctx, cancel := invocation.Start(context.Background(), invCtx)
+	defer cancel()
+	_, err := agent.Process(ctx, nil, modelstypes.Message{Role: "user"})
+	var budgetErr *invocation.BudgetExceededError
+	if !errors.As(err, &budgetErr) {
+		t.Fatalf("err = %v, want a *BudgetExceededError", err)
+	}
+	if budgetErr.Budget != invocation.BudgetToolCalls || budgetErr.Agent != "agent" || budgetErr.Limit != 2 {
+		t.Errorf("err = %+v, want the tool_calls budget of agent with limit 2", budgetErr)
+	}
+	if got := tool.calls.Load(); got != 0 {
+		t.Errorf("tool ran %d times, want no call of the batch to run", got)
+	}
+	if provider.responses != nil {
+		t.Errorf("model was called again with %+v", provider.responses)
+	}
+}
+
+func TestToolCallsWithinBudgetRun(t *testing.T) {
+	tool := &countingTool{}
+	provider := &batchProvider{}
+	agent := NewBaseLlmAgent("agent", "", "model", nil, provider, []tools.Tool{tool})
+
+	invCtx := &invocation.InvocationContext{RunConfig: &invocation.RunConfig{MaxToolCalls: 3}}
+	ctx, cancel := invocation.Start(context.Background(), invCtx)
+	defer cancel()
+	response, err := agent.Process(ctx, nil, modelstypes.Message{Role: "user"})
+	if err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+	if got := messageText(*response); got != "answer" {
+		t.Errorf("response = %q", got)
+	}
+	if got := tool.calls.Load(); got != 3 {
+		t.Errorf("tool ran %d times, want 3", got)
+	}
+	if len(provider.responses) != 3 {
+		t.Errorf("model got %d tool responses, want one per call", len(provider.responses))
+	}
+}
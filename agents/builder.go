@@ -0,0 +1,375 @@
+package agents
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/KennethanCeyer/adk-go/agents/interfaces"
+	"github.com/KennethanCeyer/adk-go/llmproviders"
+	modelstypes "github.com/KennethanCeyer/adk-go/models/types"
+	"github.com/KennethanCeyer/adk-go/tools"
+)
+
+// Option configures an agent created with one of the Build functions. Each
+// Build function accepts the options that make sense for its agent type and
+// reports the others as errors.
+type Option func(*agentSpec)
+
+type agentSpec struct {
+	used []string // Option names, in the order they were given
+
+	description         string
+	model               string
+	instruction         *modelstypes.Message
+	instructionProvider InstructionProvider
+	provider            llmproviders.LLMProvider
+	tools               []tools.Tool
+	toolsets            []tools.Toolset
+	subAgents           []interfaces.Agent
+	outputKey           string
+	generationConfig    *modelstypes.GenerationConfig
+	planner             Planner
+
+	maxIterations int
+	stopWhen      StopCondition
+	stopWhenState StateStopCondition
+	resultKey     string
+
+	errorPolicy ErrorPolicy
+	quorum      int
+	synthesizer Synthesizer
+
+	branches     []Branch
+	defaultAgent interfaces.Agent
+
+	nodes []GraphNode
+
+	generator interfaces.Agent
+	critic    interfaces.Agent
+	maxRounds int
+
+	mapper         interfaces.Agent
+	itemsKey       string
+	maxConcurrency int
+	maxRetries     int
+	retryDelay     time.Duration
+	reducer        Reducer
+	reducerAgent   interfaces.Agent
+}
+
+func option(name string, apply func(s *agentSpec)) Option {
+	return func(s *agentSpec) {
+		s.used = append(s.used, name)
+		apply(s)
+	}
+}
+
+func WithDescription(description string) Option {
+	return option("WithDescription", func(s *agentSpec) { s.description = description })
+}
+
+// WithModel sets the model ID of an LLM agent, or the model a parallel agent
+// synthesizes with or a switch agent classifies with.
+func WithModel(modelID string) Option {
+	return option("WithModel", func(s *agentSpec) { s.model = modelID })
+}
+
+func WithProvider(provider llmproviders.LLMProvider) Option {
+	return option("WithProvider", func(s *agentSpec) { s.provider = provider })
+}
+
+// WithInstruction sets the system instruction. It may reference session
+// state as {key}, see RenderInstruction.
+func WithInstruction(text string) Option {
+	return option("WithInstruction", func(s *agentSpec) {
+		s.instruction = &modelstypes.Message{Role: "system", Parts: []modelstypes.Part{{Text: &text}}}
+	})
+}
+
+func WithInstructionProvider(provider InstructionProvider) Option {
+	return option("WithInstructionProvider", func(s *agentSpec) { s.instructionProvider = provider })
+}
+
+func WithTools(agentTools ...tools.Tool) Option {
+	return option("WithTools", func(s *agentSpec) { s.tools = append(s.tools, agentTools...) })
+}
+
+func WithToolsets(toolsets ...tools.Toolset) Option {
+	return option("WithToolsets", func(s *agentSpec) { s.toolsets = append(s.toolsets, toolsets...) })
+}
+
+// WithSubAgents sets the agents a workflow agent runs, or the agents an LLM
+// agent can transfer to.
+func WithSubAgents(subAgents ...interfaces.Agent) Option {
+	return option("WithSubAgents", func(s *agentSpec) { s.subAgents = append(s.subAgents, subAgents...) })
+}
+
+func WithOutputKey(key string) Option {
+	return option("WithOutputKey", func(s *agentSpec) { s.outputKey = key })
+}
+
+func WithGenerationConfig(config *modelstypes.GenerationConfig) Option {
+	return option("WithGenerationConfig", func(s *agentSpec) { s.generationConfig = config })
+}
+
+func WithPlanner(planner Planner) Option {
+	return option("WithPlanner", func(s *agentSpec) { s.planner = planner })
+}
+
+func WithMaxIterations(n int) Option {
+	return option("WithMaxIterations", func(s *agentSpec) { s.maxIterations = n })
+}
+
+func WithStopCondition(stopWhen StopCondition) Option {
+	return option("WithStopCondition", func(s *agentSpec) { s.stopWhen = stopWhen })
+}
+
+func WithStateStopCondition(stopWhen StateStopCondition) Option {
+	return option("WithStateStopCondition", func(s *agentSpec) { s.stopWhenState = stopWhen })
+}
+
+func WithResultKey(key string) Option {
+	return option("WithResultKey", func(s *agentSpec) { s.resultKey = key })
+}
+
+func WithErrorPolicy(policy ErrorPolicy) Option {
+	return option("WithErrorPolicy", func(s *agentSpec) { s.errorPolicy = policy })
+}
+
+// WithQuorum selects the Quorum error policy with the given number of
+// required successes.
+func WithQuorum(n int) Option {
+	return option("WithQuorum", func(s *agentSpec) {
+		s.errorPolicy = Quorum
+		s.quorum = n
+	})
+}
+
+func WithSynthesizer(synthesizer Synthesizer) Option {
+	return option("WithSynthesizer", func(s *agentSpec) { s.synthesizer = synthesizer })
+}
+
+func WithBranches(branches ...Branch) Option {
+	return option("WithBranches", func(s *agentSpec) { s.branches = append(s.branches, branches...) })
+}
+
+func WithDefault(agent interfaces.Agent) Option {
+	return option("WithDefault", func(s *agentSpec) { s.defaultAgent = agent })
+}
+
+func WithNodes(nodes ...GraphNode) Option {
+	return option("WithNodes", func(s *agentSpec) { s.nodes = append(s.nodes, nodes...) })
+}
+
+func WithGenerator(agent interfaces.Agent) Option {
+	return option("WithGenerator", func(s *agentSpec) { s.generator = agent })
+}
+
+func WithCritic(agent interfaces.Agent) Option {
+	return option("WithCritic", func(s *agentSpec) { s.critic = agent })
+}
+
+func WithMaxRounds(n int) Option {
+	return option("WithMaxRounds", func(s *agentSpec) { s.maxRounds = n })
+}
+
+func WithMapper(agent interfaces.Agent) Option {
+	return option("WithMapper", func(s *agentSpec) { s.mapper = agent })
+}
+
+func WithItemsKey(key string) Option {
+	return option("WithItemsKey", func(s *agentSpec) { s.itemsKey = key })
+}
+
+func WithMaxConcurrency(n int) Option {
+	return option("WithMaxConcurrency", func(s *agentSpec) { s.maxConcurrency = n })
+}
+
+// WithRetries sets how often a failed item is retried and the delay before
+// the first retry.
+func WithRetries(n int, delay time.Duration) Option {
+	return option("WithRetries", func(s *agentSpec) {
+		s.maxRetries = n
+		s.retryDelay = delay
+	})
+}
+
+func WithReducer(reducer Reducer) Option {
+	return option("WithReducer", func(s *agentSpec) { s.reducer = reducer })
+}
+
+func WithReducerAgent(agent interfaces.Agent) Option {
+	return option("WithReducerAgent", func(s *agentSpec) { s.reducerAgent = agent })
+}
+
+// newSpec applies opts and rejects the options not listed in allowed.
+func newSpec(kind, name string, opts []Option, allowed ...string) (*agentSpec, error) {
+	s := &agentSpec{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(s)
+		}
+	}
+	var unsupported []string
+	for _, used := range s.used {
+		if !slices.Contains(allowed, used) && !slices.Contains(unsupported, used) {
+			unsupported = append(unsupported, used)
+		}
+	}
+	if len(unsupported) > 0 {
+		return nil, fmt.Errorf("%s agent '%s': %s cannot be used with %s agents", kind, name, strings.Join(unsupported, ", "), kind)
+	}
+	return s, nil
+}
+
+// BuildLlmAgent creates an LLM agent and validates it together with its
+// sub-agents. WithModel and WithProvider are required.
+func BuildLlmAgent(name string, opts ...Option) (*BaseLlmAgent, error) {
+	s, err := newSpec("llm", name, opts,
+		"WithDescription", "WithModel", "WithProvider", "WithInstruction", "WithInstructionProvider",
+		"WithTools", "WithToolsets", "WithSubAgents", "WithOutputKey", "WithGenerationConfig", "WithPlanner")
+	if err != nil {
+		return nil, err
+	}
+	a := NewBaseLlmAgent(name, s.description, s.model, s.instruction, s.provider, s.tools).(*BaseLlmAgent)
+	a.InstructionProvider = s.instructionProvider
+	a.Toolsets = s.toolsets
+	a.OutputKey = s.outputKey
+	a.GenerationConfig = s.generationConfig
+	a.Planner = s.planner
+	if err := a.AddSubAgents(s.subAgents...); err != nil {
+		return nil, err
+	}
+	if err := Validate(a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func BuildSequentialAgent(name string, opts ...Option) (*SequentialAgent, error) {
+	s, err := newSpec("sequential", name, opts, "WithDescription", "WithSubAgents")
+	if err != nil {
+		return nil, err
+	}
+	a := NewSequentialAgent(name, s.description, s.subAgents)
+	if err := Validate(a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// BuildParallelAgent creates a parallel agent. Its results are synthesized
+// by the model when WithProvider is given, see ParallelAgent.
+func BuildParallelAgent(name string, opts ...Option) (*ParallelAgent, error) {
+	s, err := newSpec("parallel", name, opts,
+		"WithDescription", "WithSubAgents", "WithModel", "WithProvider", "WithInstruction",
+		"WithErrorPolicy", "WithQuorum", "WithSynthesizer")
+	if err != nil {
+		return nil, err
+	}
+	a := NewParallelAgent(name, s.description, s.model, s.instruction, s.provider, s.subAgents)
+	a.ErrorPolicy = s.errorPolicy
+	a.Quorum = s.quorum
+	a.Synthesizer = s.synthesizer
+	if err := Validate(a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// BuildLoopAgent creates a loop agent. WithMaxIterations is required.
+func BuildLoopAgent(name string, opts ...Option) (*LoopAgent, error) {
+	s, err := newSpec("loop", name, opts,
+		"WithDescription", "WithSubAgents", "WithMaxIterations", "WithStopCondition",
+		"WithStateStopCondition", "WithResultKey")
+	if err != nil {
+		return nil, err
+	}
+	a := NewLoopAgent(name, s.description, s.subAgents, s.maxIterations, s.stopWhen)
+	a.StopWhenState = s.stopWhenState
+	a.ResultKey = s.resultKey
+	if err := Validate(a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// BuildSwitchAgent creates a switch agent. WithProvider and WithModel enable
+// the classifier.
+func BuildSwitchAgent(name string, opts ...Option) (*SwitchAgent, error) {
+	s, err := newSpec("switch", name, opts, "WithDescription", "WithBranches", "WithDefault", "WithModel", "WithProvider")
+	if err != nil {
+		return nil, err
+	}
+	a := NewSwitchAgent(name, s.description, s.branches, s.defaultAgent)
+	a.Provider = s.provider
+	a.ModelID = s.model
+	if err := Validate(a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func BuildGraphAgent(name string, opts ...Option) (*GraphAgent, error) {
+	s, err := newSpec("graph", name, opts, "WithDescription", "WithNodes")
+	if err != nil {
+		return nil, err
+	}
+	a := &GraphAgent{AgentName: name, AgentDescription: s.description, Nodes: s.nodes}
+	if err := Validate(a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// BuildReflectionAgent creates a reflection agent. WithGenerator, WithCritic
+// and WithMaxRounds are required.
+func BuildReflectionAgent(name string, opts ...Option) (*ReflectionAgent, error) {
+	s, err := newSpec("reflection", name, opts, "WithDescription", "WithGenerator", "WithCritic", "WithMaxRounds", "WithResultKey")
+	if err != nil {
+		return nil, err
+	}
+	a := NewReflectionAgent(name, s.description, s.generator, s.critic, s.maxRounds)
+	a.ResultKey = s.resultKey
+	if err := Validate(a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// BuildMapAgent creates a map agent. WithMapper is required.
+func BuildMapAgent(name string, opts ...Option) (*MapAgent, error) {
+	s, err := newSpec("map", name, opts,
+		"WithDescription", "WithMapper", "WithItemsKey", "WithMaxConcurrency", "WithRetries",
+		"WithReducer", "WithReducerAgent", "WithResultKey")
+	if err != nil {
+		return nil, err
+	}
+	a := NewMapAgent(name, s.description, s.mapper, s.itemsKey)
+	a.MaxConcurrency = s.maxConcurrency
+	a.MaxRetries = s.maxRetries
+	a.RetryDelay = s.retryDelay
+	a.Reducer = s.reducer
+	a.ReducerAgent = s.reducerAgent
+	a.ResultKey = s.resultKey
+	if err := Validate(a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// BuildFuncAgent creates an agent that runs fn, see FuncAgent.
+func BuildFuncAgent(name string, fn AgentFunc, opts ...Option) (*FuncAgent, error) {
+	s, err := newSpec("func", name, opts, "WithDescription", "WithOutputKey")
+	if err != nil {
+		return nil, err
+	}
+	a := NewFuncAgent(name, s.description, fn)
+	a.OutputKey = s.outputKey
+	if err := Validate(a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
@@ -0,0 +1,94 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/KennethanCeyer/adk-go/agents/interfaces"
+	modelstypes "github.com/KennethanCeyer/adk-go/models/types"
+)
+
+// AgentFunc is the body of a FuncAgent. It receives the same arguments as
+// Process; the session state is available through invocation.FromContext.
+type AgentFunc func(ctx context.Context, history []modelstypes.Message, latestContent modelstypes.Message) (*modelstypes.Message, error)
+
+// FuncAgent runs a plain Go function as an agent, for deterministic steps
+// such as validating input, formatting output or looking up records. It makes
+// no model calls and can be used anywhere an agent is accepted, including as
+// a step of a workflow agent.
+type FuncAgent struct {
+	AgentName        string
+	AgentDescription string
+	Func             AgentFunc
+	// OutputKey, when set, stores the function's response in the session
+	// state, like the OutputKey of an LLM agent.
+	OutputKey string
+}
+
+func NewFuncAgent(name, description string, fn AgentFunc) *FuncAgent {
+	return &FuncAgent{
+		AgentName:        name,
+		AgentDescription: description,
+		Func:             fn,
+	}
+}
+
+func (a *FuncAgent) GetName() string        { return a.AgentName }
+func (a *FuncAgent) GetDescription() string { return a.AgentDescription }
+
+func (a *FuncAgent) Process(
+	ctx context.Context,
+	history []modelstypes.Message,
+	latestContent modelstypes.Message,
+) (*modelstypes.Message, error) {
+	if a.Func == nil {
+		return nil, fmt.Errorf("func agent '%s' has no function", a.AgentName)
+	}
+	response, err := a.Func(ctx, history, latestContent)
+	if err != nil {
+		return nil, fmt.Errorf("func agent '%s' failed: %w", a.AgentName, err)
+	}
+	if response != nil && response.Role == "" {
+		response.Role = "model"
+	}
+	storeOutput(ctx, a.AgentName, a.OutputKey, response)
+	return response, nil
+}
+
+// TextFunc adapts a function from the text of the received message to the
+// text of the response into an AgentFunc.
+func TextFunc(fn func(ctx context.Context, text string) (string, error)) AgentFunc {
+	return func(ctx context.Context, _ []modelstypes.Message, latestContent modelstypes.Message) (*modelstypes.Message, error) {
+		text, err := fn(ctx, messageText(latestContent))
+		if err != nil {
+			return nil, err
+		}
+		return &modelstypes.Message{Role: "model", Parts: []modelstypes.Part{{Text: &text}}}, nil
+	}
+}
+
+// TypeName describes the kind of agent for display, e.g. "LLM Agent" or
+// "Sequential Workflow".
+func TypeName(agent interfaces.Agent) string {
+	switch agent.(type) {
+	case interfaces.LlmAgent:
+		return "LLM Agent"
+	case *SequentialAgent:
+		return "Sequential Workflow"
+	case *ParallelAgent:
+		return "Parallel Workflow"
+	case *LoopAgent:
+		return "Loop Workflow"
+	case *SwitchAgent:
+		return "Switch Workflow"
+	case *GraphAgent:
+		return "Graph Workflow"
+	case *ReflectionAgent:
+		return "Reflection Workflow"
+	case *MapAgent:
+		return "Map Workflow"
+	case *FuncAgent:
+		return "Function"
+	}
+	return "Agent"
+}
@@ -0,0 +1,311 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/KennethanCeyer/adk-go/agents/interfaces"
+	"github.com/KennethanCeyer/adk-go/agents/invocation"
+	modelstypes "github.com/KennethanCeyer/adk-go/models/types"
+)
+
+type GraphNode struct {
+	Agent interfaces.Agent
+	// DependsOn names the agents whose outputs this node needs. The node runs
+	// once all of them have finished.
+	DependsOn []string
+	// OutputKey is the session state key the node's output text is stored
+	// under. Defaults to the agent's name.
+	OutputKey string
+}
+
+func (n GraphNode) outputKey() string {
+	if n.OutputKey != "" {
+		return n.OutputKey
+	}
+	return n.Agent.GetName()
+}
+
+// GraphAgent runs its nodes as a directed acyclic graph. Nodes without
+// dependencies receive the user's message; every other node receives that
+// message together with the outputs of its upstream nodes. Nodes run in
+// waves: a node joins the wave after the last of its dependencies, the nodes
+// of a wave run concurrently in their own branches, and their state is merged
+// before the next wave starts. The response is the output of the nodes
+// nothing depends on.
+type GraphAgent struct {
+	AgentName        string
+	AgentDescription string
+	Nodes            []GraphNode
+}
+
+// NewGraphAgent returns an error if node names are not unique, a dependency
+// names an unknown node, or the dependencies form a cycle.
+func NewGraphAgent(name, description string, nodes []GraphNode) (*GraphAgent, error) {
+	a := &GraphAgent{
+		AgentName:        name,
+		AgentDescription: description,
+		Nodes:            nodes,
+	}
+	if _, err := a.TopologicalOrder(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (a *GraphAgent) GetName() string        { return a.AgentName }
+func (a *GraphAgent) GetDescription() string { return a.AgentDescription }
+
+func (a *GraphAgent) GetSubAgents() []interfaces.Agent {
+	subAgents := make([]interfaces.Agent, len(a.Nodes))
+	for i, node := range a.Nodes {
+		subAgents[i] = node.Agent
+	}
+	return subAgents
+}
+
+// TopologicalOrder validates the graph and returns the indices of its nodes
+// so that every node comes after its dependencies. Ties keep declaration
+// order.
+func (a *GraphAgent) TopologicalOrder() ([]int, error) {
+	index := make(map[string]int, len(a.Nodes))
+	for i, node := range a.Nodes {
+		if node.Agent == nil {
+			return nil, fmt.Errorf("graph '%s': node %d has no agent", a.AgentName, i+1)
+		}
+		name := node.Agent.GetName()
+		if _, exists := index[name]; exists {
+			return nil, fmt.Errorf("graph '%s': duplicate node '%s'", a.AgentName, name)
+		}
+		index[name] = i
+	}
+
+	// Depth-first search; a node found on the current path closes a cycle.
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	marks := make([]int, len(a.Nodes))
+	order := make([]int, 0, len(a.Nodes))
+	var path []string
+	var visit func(i int) error
+	visit = func(i int) error {
+		name := a.Nodes[i].Agent.GetName()
+		switch marks[i] {
+		case visited:
+			return nil
+		case visiting:
+			start := 0
+			for start < len(path) && path[start] != name {
+				start++
+			}
+			cycle := append(append([]string{}, path[start:]...), name)
+			return fmt.Errorf("graph '%s': dependency cycle %s", a.AgentName, strings.Join(cycle, " -> "))
+		}
+		marks[i] = visiting
+		path = append(path, name)
+		for _, dep := range a.Nodes[i].DependsOn {
+			j, ok := index[dep]
+			if !ok {
+				return fmt.Errorf("graph '%s': node '%s' depends on unknown node '%s'", a.AgentName, name, dep)
+			}
+			if err := visit(j); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		marks[i] = visited
+		order = append(order, i)
+		return nil
+	}
+	for i := range a.Nodes {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+func (a *GraphAgent) Process(
+	ctx context.Context,
+	history []modelstypes.Message,
+	latestContent modelstypes.Message,
+) (*modelstypes.Message, error) {
+	order, err := a.TopologicalOrder()
+	if err != nil {
+		return nil, err
+	}
+	waves := a.waves(order)
+	invocation.SendInternalLog(ctx, "Starting graph execution for %d nodes in %d waves...", len(a.Nodes), len(waves))
+
+	invCtx := invocation.FromContext(ctx)
+	outputs := make(map[string]*modelstypes.Message, len(a.Nodes))
+	for _, wave := range waves {
+		if err := a.runWave(ctx, invCtx, wave, history, latestContent, outputs); err != nil {
+			return nil, err
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	invocation.SendInternalLog(ctx, "Graph execution finished.")
+	return a.finalResponse(outputs), nil
+}
+
+// waves groups the nodes, given in topological order, by the length of the
+// longest dependency chain leading to them. Every node's dependencies are in
+// earlier waves, and the nodes of a wave keep declaration order.
+func (a *GraphAgent) waves(order []int) [][]int {
+	depth := make(map[string]int, len(a.Nodes))
+	maxDepth := 0
+	for _, i := range order {
+		d := 0
+		for _, dep := range a.Nodes[i].DependsOn {
+			if depth[dep]+1 > d {
+				d = depth[dep] + 1
+			}
+		}
+		depth[a.Nodes[i].Agent.GetName()] = d
+		if d > maxDepth {
+			maxDepth = d
+		}
+	}
+	waves := make([][]int, maxDepth+1)
+	for i, node := range a.Nodes {
+		d := depth[node.Agent.GetName()]
+		waves[d] = append(waves[d], i)
+	}
+	return waves
+}
+
+// runWave runs the nodes of a wave concurrently, each in its own branch, and
+// merges their state into invCtx once all of them have finished, so that the
+// next wave sees the outputs of this one and nothing else.
+func (a *GraphAgent) runWave(
+	ctx context.Context,
+	invCtx *invocation.InvocationContext,
+	wave []int,
+	history []modelstypes.Message,
+	latestContent modelstypes.Message,
+	outputs map[string]*modelstypes.Message,
+) error {
+	waveCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		firstErr  error
+		branches  = make([]*invocation.InvocationContext, len(wave))
+		responses = make([]*modelstypes.Message, len(wave))
+	)
+	for k, i := range wave {
+		node := a.Nodes[i]
+		// Inputs only depend on earlier waves, which are complete.
+		input := a.nodeInput(node, latestContent, outputs)
+		branches[k] = invCtx.NewBranch(a.AgentName + "." + node.Agent.GetName())
+		wg.Add(1)
+		go func(k int, node GraphNode) {
+			defer wg.Done()
+			name := node.Agent.GetName()
+			invocation.SendInternalLog(ctx, "Running graph node: %s (branch %s)", name, branches[k].Branch)
+			branchHistory := make([]modelstypes.Message, len(history))
+			copy(branchHistory, history)
+			response, err := node.Agent.Process(invocation.WithInvocationContext(waveCtx, branches[k]), branchHistory, input)
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = fmt.Errorf("graph node '%s' failed: %w", name, err)
+				}
+				mu.Unlock()
+				cancel()
+				return
+			}
+			if response != nil {
+				branches[k].SetState(node.outputKey(), messageText(*response))
+			}
+			responses[k] = response
+		}(k, node)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	for k, i := range wave {
+		outputs[a.Nodes[i].Agent.GetName()] = responses[k]
+	}
+	if err := invCtx.MergeBranches(branches...); err != nil {
+		return fmt.Errorf("graph '%s': %w", a.AgentName, err)
+	}
+	return nil
+}
+
+// nodeInput builds the message for node: the user's message as is for nodes
+// without dependencies, and otherwise the user's message followed by the
+// outputs of the upstream nodes.
+func (a *GraphAgent) nodeInput(node GraphNode, latestContent modelstypes.Message, outputs map[string]*modelstypes.Message) modelstypes.Message {
+	if len(node.DependsOn) == 0 {
+		return latestContent
+	}
+	var sb strings.Builder
+	if text := messageText(latestContent); text != "" {
+		fmt.Fprintf(&sb, "Original request:\n%s\n\n", text)
+	}
+	sb.WriteString("Outputs of the previous steps:")
+	for _, dep := range node.DependsOn {
+		output := ""
+		if response := outputs[dep]; response != nil {
+			output = messageText(*response)
+		}
+		fmt.Fprintf(&sb, "\n\n[%s]\n%s", a.node(dep).outputKey(), output)
+	}
+	text := sb.String()
+	return modelstypes.Message{Role: "user", Parts: []modelstypes.Part{{Text: &text}}}
+}
+
+func (a *GraphAgent) node(name string) GraphNode {
+	for _, node := range a.Nodes {
+		if node.Agent.GetName() == name {
+			return node
+		}
+	}
+	return GraphNode{}
+}
+
+// finalResponse returns the output of the single sink node, or joins the
+// outputs of several sinks in declaration order.
+func (a *GraphAgent) finalResponse(outputs map[string]*modelstypes.Message) *modelstypes.Message {
+	hasDependents := make(map[string]bool)
+	for _, node := range a.Nodes {
+		for _, dep := range node.DependsOn {
+			hasDependents[dep] = true
+		}
+	}
+	var sinks []GraphNode
+	for _, node := range a.Nodes {
+		if !hasDependents[node.Agent.GetName()] {
+			sinks = append(sinks, node)
+		}
+	}
+	if len(sinks) == 1 {
+		return outputs[sinks[0].Agent.GetName()]
+	}
+
+	final := &modelstypes.Message{Role: "model"}
+	for _, node := range sinks {
+		response := outputs[node.Agent.GetName()]
+		if response == nil {
+			continue
+		}
+		text := fmt.Sprintf("[%s]\n%s", node.outputKey(), messageText(*response))
+		final.Parts = append(final.Parts, modelstypes.Part{Text: &text})
+	}
+	return final
+}
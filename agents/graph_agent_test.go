@@ -0,0 +1,171 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/KennethanCeyer/adk-go/agents/invocation"
+	modelstypes "github.com/KennethanCeyer/adk-go/models/types"
+	"github.com/KennethanCeyer/adk-go/sessions"
+)
+
+func textAgent(name, text string) *FuncAgent {
+	return NewFuncAgent(name, "", func(context.Context, []modelstypes.Message, modelstypes.Message) (*modelstypes.Message, error) {
+		reply := text
+		return &modelstypes.Message{Role: "model", Parts: []modelstypes.Part{{Text: &reply}}}, nil
+	})
+}
+
+func TestNewGraphAgentRejectsInvalidGraphs(t *testing.T) {
+	tests := []struct {
+		name  string
+		nodes []GraphNode
+		want  string
+	}{
+		{
+			name: "cycle",
+			nodes: []GraphNode{
+				{Agent: textAgent("a", ""), DependsOn: []string{"c"}},
+				{Agent: textAgent("b", ""), DependsOn: []string{"a"}},
+				{Agent: textAgent("c", ""), DependsOn: []string{"b"}},
+			},
+			want: "dependency cycle a -> c -> b -> a",
+		},
+		{
+			name:  "self dependency",
+			nodes: []GraphNode{{Agent: textAgent("a", ""), DependsOn: []string{"a"}}},
+			want:  "dependency cycle a -> a",
+		},
+		{
+			name:  "unknown dependency",
+			nodes: []GraphNode{{Agent: textAgent("a", ""), DependsOn: []string{"missing"}}},
+			want:  "node 'a' depends on unknown node 'missing'",
+		},
+		{
+			name:  "duplicate node",
+			nodes: []GraphNode{{Agent: textAgent("a", "")}, {Agent: textAgent("a", "")}},
+			want:  "duplicate node 'a'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewGraphAgent("g", "", tt.nodes)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestGraphAgentRunsIndependentNodesConcurrently(t *testing.T) {
+	// Each root node waits until the other has started, so the graph only
+	// finishes if both run at the same time.
+	var started sync.WaitGroup
+	started.Add(2)
+	root := func(name string) *FuncAgent {
+		return NewFuncAgent(name, "", func(ctx context.Context, _ []modelstypes.Message, _ modelstypes.Message) (*modelstypes.Message, error) {
+			started.Done()
+			waited := make(chan struct{})
+			go func() {
+				started.Wait()
+				close(waited)
+			}()
+			select {
+			case <-waited:
+			case <-time.After(5 * time.Second):
+				return nil, errors.New("sibling never started")
+			}
+			text := name
+			return &modelstypes.Message{Role: "model", Parts: []modelstypes.Part{{Text: &text}}}, nil
+		})
+	}
+	agent, err := NewGraphAgent("g", "", []GraphNode{
+		{Agent: root("left")},
+		{Agent: root("right")},
+		{Agent: textAgent("join", "joined"), DependsOn: []string{"left", "right"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, err := agent.Process(context.Background(), nil, modelstypes.Message{Role: "user"})
+	if err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+	if got := messageText(*response); got != "joined" {
+		t.Errorf("response = %q, want the sink's output", got)
+	}
+}
+
+func TestGraphAgentPassesOutputsThroughState(t *testing.T) {
+	var seenState any
+	var seenInput string
+	summarize := NewFuncAgent("summarize", "", func(ctx context.Context, _ []modelstypes.Message, input modelstypes.Message) (*modelstypes.Message, error) {
+		seenState, _ = invocation.FromContext(ctx).GetState("research")
+		seenInput = messageText(input)
+		text := "summary"
+		return &modelstypes.Message{Role: "model", Parts: []modelstypes.Part{{Text: &text}}}, nil
+	})
+	agent, err := NewGraphAgent("g", "", []GraphNode{
+		{Agent: textAgent("fetch", "facts"), OutputKey: "research"},
+		{Agent: summarize, DependsOn: []string{"fetch"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sess := &sessions.Session{ID: "s"}
+	ctx := invocation.WithInvocationContext(context.Background(), &invocation.InvocationContext{Session: sess})
+	if _, err := agent.Process(ctx, nil, modelstypes.Message{Role: "user"}); err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+	if seenState != "facts" {
+		t.Errorf("downstream node saw research = %v, want facts", seenState)
+	}
+	if !strings.Contains(seenInput, "[research]\nfacts") {
+		t.Errorf("downstream input = %q, want the upstream output", seenInput)
+	}
+	for key, want := range map[string]string{"research": "facts", "summarize": "summary"} {
+		if v, _ := sess.GetState(key); v != want {
+			t.Errorf("session %s = %v, want %q", key, v, want)
+		}
+	}
+}
+
+func TestGraphAgentIsolatesSiblings(t *testing.T) {
+	var mu sync.Mutex
+	seen := make(map[string]any)
+	writer := func(name, value string) *FuncAgent {
+		return NewFuncAgent(name, "", func(ctx context.Context, _ []modelstypes.Message, _ modelstypes.Message) (*modelstypes.Message, error) {
+			invCtx := invocation.FromContext(ctx)
+			invCtx.SetState("shared", value)
+			time.Sleep(10 * time.Millisecond)
+			v, _ := invCtx.GetState("shared")
+			mu.Lock()
+			seen[name] = v
+			mu.Unlock()
+			return &modelstypes.Message{Role: "model"}, nil
+		})
+	}
+	agent, err := NewGraphAgent("g", "", []GraphNode{{Agent: writer("a", "from a")}, {Agent: writer("b", "from b")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sess := &sessions.Session{ID: "s"}
+	ctx := invocation.WithInvocationContext(context.Background(), &invocation.InvocationContext{Session: sess})
+	_, err = agent.Process(ctx, nil, modelstypes.Message{Role: "user"})
+	var conflict *invocation.StateConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("err = %v, want a state conflict", err)
+	}
+	if seen["a"] != "from a" || seen["b"] != "from b" {
+		t.Errorf("siblings saw %v, want each to see only its own write", seen)
+	}
+	if v, ok := sess.GetState("shared"); ok {
+		t.Errorf("shared = %v, want the conflicting key left out", v)
+	}
+}
@@ -0,0 +1,207 @@
+package agents
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/KennethanCeyer/adk-go/agents/invocation"
+	modelstypes "github.com/KennethanCeyer/adk-go/models/types"
+	"github.com/KennethanCeyer/adk-go/sessions"
+)
+
+// ReadonlyContext gives instruction providers read access to the invocation
+// the instruction is built for. It embeds the invocation's context.Context.
+type ReadonlyContext struct {
+	context.Context
+	InvocationID string
+	AgentName    string
+	// UserContent is the message the agent is answering.
+	UserContent *modelstypes.Message
+
+	invCtx *invocation.InvocationContext
+}
+
+func newReadonlyContext(ctx context.Context, agentName string, userContent *modelstypes.Message) ReadonlyContext {
+	invCtx := invocation.FromContext(ctx)
+	return ReadonlyContext{
+		Context:      ctx,
+		InvocationID: invCtx.ID,
+		AgentName:    agentName,
+		UserContent:  userContent,
+		invCtx:       invCtx,
+	}
+}
+
+// State returns a copy of the state visible to the agent, which is empty
+// when the agent runs outside a session.
+func (c ReadonlyContext) State() map[string]any {
+	return c.invCtx.StateSnapshot()
+}
+
+// Artifact returns the session artifact stored under name.
+func (c ReadonlyContext) Artifact(name string) (*sessions.Artifact, bool) {
+	if c.invCtx.Session == nil {
+		return nil, false
+	}
+	return c.invCtx.Session.LoadArtifact(name)
+}
+
+// InstructionProvider builds the system instruction for a model call.
+type InstructionProvider func(ctx ReadonlyContext) (string, error)
+
+// placeholderPattern matches {name}, {name?}, {artifact.file.txt} and
+// {artifact.file.txt?}. Other text in braces, such as JSON, is left alone.
+var placeholderPattern = regexp.MustCompile(`^\{([A-Za-z_][A-Za-z0-9_]*|artifact\.[A-Za-z0-9_./-]+)(\??)\}`)
+
+// RenderInstruction fills the placeholders in an instruction template:
+//
+//   - {name} is replaced with the session state value stored under name.
+//     Strings are inserted as is, other values as JSON.
+//   - {name?} is the same, but is replaced with nothing if name is not set.
+//   - {artifact.report.txt} is replaced with the content of the session
+//     artifact called report.txt, and may be made optional with a trailing ?.
+//   - {{ produces a literal brace, and so does a }} that closes it, so
+//     {{name}} renders as {name}. Any other }, such as the end of a nested
+//     JSON object, is kept as is.
+//
+// Placeholders that are required but missing are reported together in the
+// returned error.
+func RenderInstruction(template string, ctx ReadonlyContext) (string, error) {
+	var state map[string]any
+	var sb strings.Builder
+	var missing []string
+	openEscapes := 0 // {{ escapes waiting for their }}
+	for i := 0; i < len(template); {
+		switch {
+		case strings.HasPrefix(template[i:], "{{"):
+			sb.WriteByte('{')
+			openEscapes++
+			i += 2
+			continue
+		case openEscapes > 0 && strings.HasPrefix(template[i:], "}}"):
+			sb.WriteByte('}')
+			openEscapes--
+			i += 2
+			continue
+		case template[i] != '{':
+			sb.WriteByte(template[i])
+			i++
+			continue
+		}
+
+		m := placeholderPattern.FindStringSubmatch(template[i:])
+		if m == nil {
+			sb.WriteByte('{')
+			i++
+			continue
+		}
+		i += len(m[0])
+		name, optional := m[1], m[2] == "?"
+
+		if artifactName, ok := strings.CutPrefix(name, "artifact."); ok {
+			if artifact, found := ctx.Artifact(artifactName); found {
+				sb.Write(artifact.Data)
+			} else if !optional {
+				missing = append(missing, name)
+			}
+			continue
+		}
+		if state == nil {
+			state = ctx.State()
+		}
+		if value, found := state[name]; found {
+			sb.WriteString(formatStateValue(value))
+		} else if !optional {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("instruction references missing variables: %s (append '?' to make a variable optional, or write '{{' to escape a brace)", strings.Join(missing, ", "))
+	}
+	return sb.String(), nil
+}
+
+// formatStateValue renders strings as is and other values as JSON.
+func formatStateValue(value any) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
+
+// instruction returns the system instruction for a model call: the output of
+// InstructionProvider if set, or else the static instruction with its
+// placeholders rendered.
+func (a *BaseLlmAgent) instruction(ctx context.Context, userContent *modelstypes.Message) (*modelstypes.Message, error) {
+	rc := newReadonlyContext(ctx, a.name, userContent)
+	if a.InstructionProvider != nil {
+		text, err := a.InstructionProvider(rc)
+		if err != nil {
+			return nil, fmt.Errorf("agent '%s' failed to build its instruction: %w", a.name, err)
+		}
+		return &modelstypes.Message{Role: "system", Parts: []modelstypes.Part{{Text: &text}}}, nil
+	}
+	if a.systemInstruction == nil {
+		return nil, nil
+	}
+
+	msg := *a.systemInstruction
+	msg.Parts = make([]modelstypes.Part, len(a.systemInstruction.Parts))
+	for i, part := range a.systemInstruction.Parts {
+		if part.Text != nil {
+			text, err := RenderInstruction(*part.Text, rc)
+			if err != nil {
+				return nil, fmt.Errorf("agent '%s': %w", a.name, err)
+			}
+			part.Text = &text
+		}
+		msg.Parts[i] = part
+	}
+	return &msg, nil
+}
+
+// saveOutput stores the text of the agent's final response in the session
+// state under OutputKey.
+func (a *BaseLlmAgent) saveOutput(ctx context.Context, response *modelstypes.Message) {
+	storeOutput(ctx, a.name, a.OutputKey, response)
+}
+
+// storeOutput stores the text of an agent's response in the session state
+// under key. Responses that are a JSON object or array, possibly inside a
+// ```json fence, are stored in parsed form.
+func storeOutput(ctx context.Context, agentName, key string, response *modelstypes.Message) {
+	invCtx := invocation.FromContext(ctx)
+	if key == "" || invCtx.Session == nil || response == nil {
+		return
+	}
+	text := messageText(*response)
+	var value any = text
+	if structured, ok := parseStructuredOutput(text); ok {
+		value = structured
+	}
+	invCtx.SetState(key, value)
+	invocation.SendInternalLog(ctx, "  - Agent '%s' stored its output in state key '%s'", agentName, key)
+}
+
+func parseStructuredOutput(text string) (any, bool) {
+	trimmed := strings.TrimSpace(text)
+	if strings.HasPrefix(trimmed, "```") && strings.HasSuffix(trimmed, "```") && len(trimmed) >= 6 {
+		trimmed = strings.TrimSpace(trimmed[3 : len(trimmed)-3])
+		trimmed = strings.TrimSpace(strings.TrimPrefix(trimmed, "json"))
+	}
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return nil, false
+	}
+	var value any
+	if err := json.Unmarshal([]byte(trimmed), &value); err != nil {
+		return nil, false
+	}
+	return value, true
+}
@@ -0,0 +1,60 @@
+package agents
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/KennethanCeyer/adk-go/agents/invocation"
+	"github.com/KennethanCeyer/adk-go/sessions"
+)
+
+func TestRenderInstruction(t *testing.T) {
+	sess := &sessions.Session{ID: "s"}
+	sess.SetState("topic", "go")
+	sess.SetState("limits", map[string]any{"words": 100})
+	sess.SaveArtifact("notes.txt", "text/plain", []byte("remember this"))
+	ctx := invocation.WithInvocationContext(context.Background(), &invocation.InvocationContext{Session: sess})
+	rc := newReadonlyContext(ctx, "writer", nil)
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{"variable", "Write about {topic}.", "Write about go."},
+		{"structured variable", "Limits: {limits}", `Limits: {"words":100}`},
+		{"optional variable set", "Topic: {topic?}", "Topic: go"},
+		{"optional variable missing", "Tone: {tone?}.", "Tone: ."},
+		{"artifact", "Notes: {artifact.notes.txt}", "Notes: remember this"},
+		{"optional artifact missing", "Draft: {artifact.draft.txt?}", "Draft: "},
+		{"escaped braces", "Use {{topic}} literally.", "Use {topic} literally."},
+		{"escaped open brace only", "A {{ alone", "A { alone"},
+		{"json", `Reply as {"answer": "..."}.`, `Reply as {"answer": "..."}.`},
+		{"nested json", `Example: {"a":{"b":1}}`, `Example: {"a":{"b":1}}`},
+		{"escaped nested json", `Example: {{"a":{"b":{topic}}}}`, `Example: {"a":{"b":go}}`},
+		{"closing braces without escape", "}} and }", "}} and }"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderInstruction(tt.template, rc)
+			if err != nil {
+				t.Fatalf("RenderInstruction(%q): %v", tt.template, err)
+			}
+			if got != tt.want {
+				t.Errorf("RenderInstruction(%q) = %q, want %q", tt.template, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderInstructionReportsAllMissingVariables(t *testing.T) {
+	rc := newReadonlyContext(context.Background(), "writer", nil)
+	_, err := RenderInstruction("{topic} {tone?} {artifact.notes.txt} {audience}", rc)
+	if err == nil {
+		t.Fatal("err = nil, want the missing variables reported")
+	}
+	if !strings.Contains(err.Error(), "missing variables: topic, artifact.notes.txt, audience") {
+		t.Errorf("err = %v, want every required missing variable listed in order", err)
+	}
+}
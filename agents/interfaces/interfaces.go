@@ -8,11 +8,21 @@ import (
 	"github.com/KennethanCeyer/adk-go/tools"
 )
 
+// Agent is anything that can take part in a conversation: an LLM agent, a
+// workflow agent that coordinates other agents, or a deterministic step
+// written in plain Go.
 type Agent interface {
 	GetName() string
 	GetDescription() string
+
+	Process(
+		ctx context.Context,
+		history []modelstypes.Message,
+		latestMessage modelstypes.Message,
+	) (*modelstypes.Message, error)
 }
 
+// LlmAgent is an Agent backed by a model.
 type LlmAgent interface {
 	Agent // Embeds base Agent capabilities
 
@@ -20,10 +30,9 @@ type LlmAgent interface {
 	GetSystemInstruction() *modelstypes.Message
 	GetTools() []tools.Tool
 	GetLLMProvider() llmproviders.LLMProvider
+}
 
-	Process(
-		ctx context.Context,
-		history []modelstypes.Message,
-		latestMessage modelstypes.Message,
-	) (*modelstypes.Message, error)
+// ParentAgent is implemented by agents that contain sub-agents.
+type ParentAgent interface {
+	GetSubAgents() []Agent
 }
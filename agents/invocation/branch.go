@@ -0,0 +1,180 @@
+package invocation
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/KennethanCeyer/adk-go/common/types"
+)
+
+// stateOverlay isolates the state of a branch: reads see the parent's state
+// as it was when the branch was created plus the branch's own writes, and
+// writes stay in the overlay until they are merged back.
+type stateOverlay struct {
+	base   map[string]any
+	writes map[string]any
+}
+
+// GetState returns the value stored under key in the state visible to this
+// invocation: the branch's overlay if it runs in a branch, or else the
+// session state.
+func (c *InvocationContext) GetState(key string) (any, bool) {
+	c.mu.Lock()
+	overlay := c.overlay
+	if overlay != nil {
+		defer c.mu.Unlock()
+		if value, ok := overlay.writes[key]; ok {
+			return value, true
+		}
+		value, ok := overlay.base[key]
+		return value, ok
+	}
+	c.mu.Unlock()
+	if c.Session == nil {
+		return nil, false
+	}
+	return c.Session.GetState(key)
+}
+
+// SetState stores value under key. Inside a branch the write is only visible
+// to the branch until MergeBranches is called on its parent. Outside a
+// session and a branch, the write is dropped.
+func (c *InvocationContext) SetState(key string, value any) {
+	c.mu.Lock()
+	if c.overlay != nil {
+		defer c.mu.Unlock()
+		c.overlay.writes[key] = value
+		return
+	}
+	c.mu.Unlock()
+	if c.Session != nil {
+		c.Session.SetState(key, value)
+	}
+}
+
+// StateSnapshot returns a copy of the state visible to this invocation.
+func (c *InvocationContext) StateSnapshot() map[string]any {
+	c.mu.Lock()
+	if overlay := c.overlay; overlay != nil {
+		defer c.mu.Unlock()
+		state := make(map[string]any, len(overlay.base)+len(overlay.writes))
+		for k, v := range overlay.base {
+			state[k] = v
+		}
+		for k, v := range overlay.writes {
+			state[k] = v
+		}
+		return state
+	}
+	c.mu.Unlock()
+	if c.Session == nil {
+		return map[string]any{}
+	}
+	return c.Session.CopyState()
+}
+
+// NewBranch returns the invocation context for a sub-agent that runs
+// concurrently with its siblings. The branch shares the invocation ID,
+// session and artifacts, but gets its own ID below c's and its own state
+// overlay, so that siblings cannot see or overwrite each other's state.
+func (c *InvocationContext) NewBranch(name string) *InvocationContext {
+	branch := types.BranchID(name)
+	if c.Branch != "" {
+		branch = c.Branch + "." + branch
+	}
+	return &InvocationContext{
+		ID:        c.ID,
+		Agent:     c.Agent,
+		Session:   c.Session,
+		Branch:    branch,
+		RunConfig: c.RunConfig,
+		overlay:   &stateOverlay{base: c.StateSnapshot(), writes: make(map[string]any)},
+		spending:  c.spent(),
+	}
+}
+
+// StateConflict describes a state key that several branches set to
+// different values.
+type StateConflict struct {
+	Key      string
+	Branches []types.BranchID
+}
+
+// StateConflictError is returned by MergeBranches when branches disagree on
+// the value of one or more keys. None of the conflicting keys is merged.
+type StateConflictError struct {
+	Conflicts []StateConflict
+}
+
+func (e *StateConflictError) Error() string {
+	descriptions := make([]string, len(e.Conflicts))
+	for i, conflict := range e.Conflicts {
+		branches := make([]string, len(conflict.Branches))
+		for j, b := range conflict.Branches {
+			branches[j] = string(b)
+		}
+		descriptions[i] = fmt.Sprintf("'%s' (written by %s)", conflict.Key, strings.Join(branches, ", "))
+	}
+	return "conflicting state writes from parallel branches: " + strings.Join(descriptions, "; ")
+}
+
+// MergeBranches applies the state writes of branches to c. Keys are merged
+// in sorted order, so the result does not depend on which branch finished
+// first. Branches writing equal values to a key do not conflict; keys written
+// with different values are left out and reported in a *StateConflictError.
+// An escalation raised in any of the branches is passed on to c.
+func (c *InvocationContext) MergeBranches(branches ...*InvocationContext) error {
+	for _, b := range branches {
+		if reason, escalated := b.TakeEscalation(); escalated {
+			c.Escalate(reason)
+		}
+	}
+
+	type write struct {
+		value    any
+		branches []types.BranchID
+		conflict bool
+	}
+	writes := make(map[string]*write)
+	for _, b := range branches {
+		b.mu.Lock()
+		var branchWrites map[string]any
+		if b.overlay != nil {
+			branchWrites = b.overlay.writes
+		}
+		for key, value := range branchWrites {
+			w, ok := writes[key]
+			if !ok {
+				writes[key] = &write{value: value, branches: []types.BranchID{b.Branch}}
+				continue
+			}
+			w.branches = append(w.branches, b.Branch)
+			if !reflect.DeepEqual(w.value, value) {
+				w.conflict = true
+			}
+		}
+		b.mu.Unlock()
+	}
+
+	keys := make([]string, 0, len(writes))
+	for key := range writes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var conflicts []StateConflict
+	for _, key := range keys {
+		w := writes[key]
+		if w.conflict {
+			conflicts = append(conflicts, StateConflict{Key: key, Branches: w.branches})
+			continue
+		}
+		c.SetState(key, w.value)
+	}
+	if len(conflicts) > 0 {
+		return &StateConflictError{Conflicts: conflicts}
+	}
+	return nil
+}
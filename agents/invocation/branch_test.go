@@ -0,0 +1,103 @@
+package invocation
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/KennethanCeyer/adk-go/common/types"
+	"github.com/KennethanCeyer/adk-go/sessions"
+)
+
+func newTestInvocation() *InvocationContext {
+	return &InvocationContext{ID: "inv", Session: &sessions.Session{ID: "s", State: map[string]any{"shared": "base"}}}
+}
+
+func TestBranchStateIsIsolated(t *testing.T) {
+	parent := newTestInvocation()
+	a, b := parent.NewBranch("a"), parent.NewBranch("b")
+
+	a.SetState("shared", "from a")
+	if v, _ := a.GetState("shared"); v != "from a" {
+		t.Errorf("branch a reads %v, want its own write", v)
+	}
+	if v, _ := b.GetState("shared"); v != "base" {
+		t.Errorf("branch b reads %v, want the state from before the branches started", v)
+	}
+	if v, _ := parent.GetState("shared"); v != "base" {
+		t.Errorf("parent reads %v before the merge", v)
+	}
+	if a.Branch != "a" || a.NewBranch("x").Branch != "a.x" {
+		t.Errorf("branch IDs = %q, %q", a.Branch, a.NewBranch("x").Branch)
+	}
+}
+
+func TestMergeBranchesIsDeterministic(t *testing.T) {
+	merge := func(order ...int) map[string]any {
+		parent := newTestInvocation()
+		branches := []*InvocationContext{parent.NewBranch("a"), parent.NewBranch("b"), parent.NewBranch("c")}
+		branches[0].SetState("alpha", 1)
+		branches[1].SetState("beta", []any{"x"})
+		branches[2].SetState("gamma", map[string]any{"k": "v"})
+		ordered := make([]*InvocationContext, len(order))
+		for i, index := range order {
+			ordered[i] = branches[index]
+		}
+		if err := parent.MergeBranches(ordered...); err != nil {
+			t.Fatalf("MergeBranches: %v", err)
+		}
+		return parent.Session.CopyState()
+	}
+
+	want := map[string]any{"shared": "base", "alpha": 1, "beta": []any{"x"}, "gamma": map[string]any{"k": "v"}}
+	for _, order := range [][]int{{0, 1, 2}, {2, 1, 0}, {1, 2, 0}} {
+		if got := merge(order...); !reflect.DeepEqual(got, want) {
+			t.Errorf("merge in order %v = %v, want %v", order, got, want)
+		}
+	}
+}
+
+func TestMergeBranchesEqualValuesDoNotConflict(t *testing.T) {
+	parent := newTestInvocation()
+	a, b := parent.NewBranch("a"), parent.NewBranch("b")
+	a.SetState("result", map[string]any{"n": 1})
+	b.SetState("result", map[string]any{"n": 1})
+
+	if err := parent.MergeBranches(a, b); err != nil {
+		t.Fatalf("MergeBranches: %v", err)
+	}
+	if v, _ := parent.GetState("result"); !reflect.DeepEqual(v, map[string]any{"n": 1}) {
+		t.Errorf("result = %v", v)
+	}
+}
+
+func TestMergeBranchesReportsConflicts(t *testing.T) {
+	parent := newTestInvocation()
+	a, b, c := parent.NewBranch("a"), parent.NewBranch("b"), parent.NewBranch("c")
+	a.SetState("z_key", "a")
+	b.SetState("z_key", "b")
+	b.SetState("a_key", 1)
+	c.SetState("a_key", 2)
+	c.SetState("ok", true)
+
+	err := parent.MergeBranches(c, b, a)
+	var conflictErr *StateConflictError
+	if !errors.As(err, &conflictErr) {
+		t.Fatalf("err = %v, want a *StateConflictError", err)
+	}
+	want := []StateConflict{
+		{Key: "a_key", Branches: []types.BranchID{"c", "b"}},
+		{Key: "z_key", Branches: []types.BranchID{"b", "a"}},
+	}
+	if !reflect.DeepEqual(conflictErr.Conflicts, want) {
+		t.Errorf("conflicts = %+v, want %+v", conflictErr.Conflicts, want)
+	}
+	for _, key := range []string{"a_key", "z_key"} {
+		if v, ok := parent.GetState(key); ok {
+			t.Errorf("conflicting key %q was merged with %v", key, v)
+		}
+	}
+	if v, _ := parent.GetState("ok"); v != true {
+		t.Errorf("non-conflicting key was not merged: %v", v)
+	}
+}
@@ -0,0 +1,251 @@
+package invocation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	modelstypes "github.com/KennethanCeyer/adk-go/models/types"
+)
+
+// DefaultMaxStepsPerAgent is the number of model calls an LLM agent may make
+// while answering one message when RunConfig.MaxStepsPerAgent is not set.
+const DefaultMaxStepsPerAgent = 10
+
+// RunConfig limits what one invocation may spend. The limits apply to the
+// agent the invocation starts and every agent nested below it, including
+// parallel branches and transfers. Zero values mean no limit.
+type RunConfig struct {
+	MaxLLMCalls  int
+	MaxToolCalls int
+	MaxTokens    int     // Total tokens reported by the model providers
+	MaxCost      float64 // In the currency of Pricing
+	// Pricing gives the price of each model's tokens for MaxCost. Calls to
+	// models without a price count as free.
+	Pricing map[string]ModelPricing
+	// MaxDuration is the wall time the invocation may take.
+	MaxDuration time.Duration
+	// MaxStepsPerAgent caps the model calls one LLM agent makes while
+	// answering one message. Defaults to DefaultMaxStepsPerAgent.
+	MaxStepsPerAgent int
+}
+
+// ModelPricing is the price of a million tokens of a model.
+type ModelPricing struct {
+	InputPerMillion  float64 `json:"inputPerMillion"`
+	OutputPerMillion float64 `json:"outputPerMillion"`
+}
+
+func (c *RunConfig) maxStepsPerAgent() int {
+	if c == nil || c.MaxStepsPerAgent <= 0 {
+		return DefaultMaxStepsPerAgent
+	}
+	return c.MaxStepsPerAgent
+}
+
+// Budget names a limit of RunConfig.
+type Budget string
+
+const (
+	BudgetLLMCalls   Budget = "llm_calls"
+	BudgetToolCalls  Budget = "tool_calls"
+	BudgetTokens     Budget = "tokens"
+	BudgetCost       Budget = "cost"
+	BudgetDeadline   Budget = "deadline"
+	BudgetAgentSteps Budget = "agent_steps"
+)
+
+// BudgetExceededError is returned when an invocation runs out of one of the
+// budgets of its RunConfig. Agents stop as soon as they see it, so it is
+// passed up through every enclosing workflow agent.
+type BudgetExceededError struct {
+	Budget Budget
+	Agent  string  // The agent that ran out
+	Limit  float64 // For BudgetDeadline, in seconds
+	Used   float64
+}
+
+func (e *BudgetExceededError) Error() string {
+	switch e.Budget {
+	case BudgetDeadline:
+		return fmt.Sprintf("agent '%s' ran out of time: the invocation may take %s", e.Agent, time.Duration(e.Limit*float64(time.Second)))
+	case BudgetAgentSteps:
+		return fmt.Sprintf("agent '%s' made %g model calls without finishing its answer", e.Agent, e.Used)
+	}
+	return fmt.Sprintf("agent '%s' exceeded the %s budget: used %.6g of %.6g", e.Agent, e.Budget, e.Used, e.Limit)
+}
+
+// Usage is what an invocation has spent so far.
+type Usage struct {
+	LLMCalls  int
+	ToolCalls int
+	Tokens    int
+	Cost      float64
+	Elapsed   time.Duration
+}
+
+// spending is shared by an invocation context and all of its branches.
+type spending struct {
+	mu        sync.Mutex
+	started   time.Time
+	llmCalls  int
+	toolCalls int
+	tokens    int
+	cost      float64
+}
+
+var runConfigKey = contextKey("runConfig")
+
+// WithRunConfig makes the invocations started with ctx use config.
+func WithRunConfig(ctx context.Context, config *RunConfig) context.Context {
+	return context.WithValue(ctx, runConfigKey, config)
+}
+
+func GetRunConfig(ctx context.Context) *RunConfig {
+	config, _ := ctx.Value(runConfigKey).(*RunConfig)
+	return config
+}
+
+// Start attaches c to ctx and starts the clock of its budgets. If the
+// RunConfig sets MaxDuration, the returned context is cancelled when it
+// passes; the cancel function must be called when the invocation is done.
+func Start(ctx context.Context, c *InvocationContext) (context.Context, context.CancelFunc) {
+	c.mu.Lock()
+	c.spending = &spending{started: time.Now()}
+	c.mu.Unlock()
+	ctx = WithInvocationContext(ctx, c)
+	if c.RunConfig != nil && c.RunConfig.MaxDuration > 0 {
+		return context.WithTimeout(ctx, c.RunConfig.MaxDuration)
+	}
+	return context.WithCancel(ctx)
+}
+
+// spent returns the spending shared with the branches of c, creating it if
+// the invocation was not started with Start.
+func (c *InvocationContext) spent() *spending {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.spending == nil {
+		c.spending = &spending{started: time.Now()}
+	}
+	return c.spending
+}
+
+// Usage returns what the invocation has spent so far.
+func (c *InvocationContext) Usage() Usage {
+	s := c.spent()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return Usage{
+		LLMCalls:  s.llmCalls,
+		ToolCalls: s.toolCalls,
+		Tokens:    s.tokens,
+		Cost:      s.cost,
+		Elapsed:   time.Since(s.started),
+	}
+}
+
+// MaxStepsPerAgent returns how many model calls an LLM agent may make while
+// answering one message.
+func (c *InvocationContext) MaxStepsPerAgent() int {
+	return c.RunConfig.maxStepsPerAgent()
+}
+
+// BeginLLMCall counts a model call by agentName, or returns a
+// *BudgetExceededError if the invocation may not make it.
+func (c *InvocationContext) BeginLLMCall(agentName string) error {
+	s := c.spent()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := c.checkLocked(s, agentName); err != nil {
+		return err
+	}
+	if limit := c.limits().MaxLLMCalls; limit > 0 && s.llmCalls >= limit {
+		return &BudgetExceededError{Budget: BudgetLLMCalls, Agent: agentName, Limit: float64(limit), Used: float64(s.llmCalls)}
+	}
+	s.llmCalls++
+	return nil
+}
+
+// BeginToolCall counts a tool call by agentName, or returns a
+// *BudgetExceededError if the invocation may not make it.
+func (c *InvocationContext) BeginToolCall(agentName string) error {
+	s := c.spent()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := c.checkLocked(s, agentName); err != nil {
+		return err
+	}
+	if limit := c.limits().MaxToolCalls; limit > 0 && s.toolCalls >= limit {
+		return &BudgetExceededError{Budget: BudgetToolCalls, Agent: agentName, Limit: float64(limit), Used: float64(s.toolCalls)}
+	}
+	s.toolCalls++
+	return nil
+}
+
+// RecordUsage adds the tokens a model call by agentName used, and their
+// cost, to the invocation's spending. It returns a *BudgetExceededError if
+// this call used up the token or cost budget.
+func (c *InvocationContext) RecordUsage(agentName, model string, usage *modelstypes.UsageMetadata) error {
+	if usage == nil {
+		return nil
+	}
+	s := c.spent()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	limits := c.limits()
+	s.tokens += usage.TotalTokenCount
+	if price, ok := limits.Pricing[model]; ok {
+		s.cost += (float64(usage.PromptTokenCount)*price.InputPerMillion + float64(usage.CandidatesTokenCount)*price.OutputPerMillion) / 1e6
+	}
+	if limits.MaxTokens > 0 && s.tokens > limits.MaxTokens {
+		return &BudgetExceededError{Budget: BudgetTokens, Agent: agentName, Limit: float64(limits.MaxTokens), Used: float64(s.tokens)}
+	}
+	if limits.MaxCost > 0 && s.cost > limits.MaxCost {
+		return &BudgetExceededError{Budget: BudgetCost, Agent: agentName, Limit: limits.MaxCost, Used: s.cost}
+	}
+	return nil
+}
+
+// BudgetErr returns err as a *BudgetExceededError if it was caused by the
+// invocation running past its MaxDuration, and err unchanged otherwise.
+func (c *InvocationContext) BudgetErr(err error, agentName string) error {
+	var exceeded *BudgetExceededError
+	if err == nil || errors.As(err, &exceeded) || !errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	s := c.spent()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if deadlineErr := c.checkLocked(s, agentName); deadlineErr != nil {
+		return deadlineErr
+	}
+	return err
+}
+
+// checkLocked reports whether the invocation is past its MaxDuration, or
+// has used up its token or cost budget on earlier calls.
+func (c *InvocationContext) checkLocked(s *spending, agentName string) error {
+	limits := c.limits()
+	if limits.MaxDuration > 0 {
+		if elapsed := time.Since(s.started); elapsed >= limits.MaxDuration {
+			return &BudgetExceededError{Budget: BudgetDeadline, Agent: agentName, Limit: limits.MaxDuration.Seconds(), Used: elapsed.Seconds()}
+		}
+	}
+	if limits.MaxTokens > 0 && s.tokens >= limits.MaxTokens {
+		return &BudgetExceededError{Budget: BudgetTokens, Agent: agentName, Limit: float64(limits.MaxTokens), Used: float64(s.tokens)}
+	}
+	if limits.MaxCost > 0 && s.cost >= limits.MaxCost {
+		return &BudgetExceededError{Budget: BudgetCost, Agent: agentName, Limit: limits.MaxCost, Used: s.cost}
+	}
+	return nil
+}
+
+func (c *InvocationContext) limits() RunConfig {
+	if c.RunConfig == nil {
+		return RunConfig{}
+	}
+	return *c.RunConfig
+}
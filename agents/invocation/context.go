@@ -3,8 +3,11 @@ package invocation
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"github.com/KennethanCeyer/adk-go/agents/interfaces"
+	"github.com/KennethanCeyer/adk-go/common/types"
+	"github.com/KennethanCeyer/adk-go/sessions"
 )
 
 type contextKey string
@@ -12,11 +15,88 @@ type contextKey string
 const (
 	invocationContextKey = contextKey("invocationContext")
 	uiSenderKey = contextKey("uiSender")
+	confirmerKey = contextKey("confirmer")
 )
 
 type InvocationContext struct {
-	ID    string
-	Agent interfaces.LlmAgent
+	ID      string
+	Agent   interfaces.Agent
+	Session *sessions.Session // May be nil when the agent runs outside a session
+	// Branch identifies the concurrent branch the agent runs in, such as
+	// "trip_planner.FlightAgent". Empty outside of parallel execution.
+	Branch types.BranchID
+	// RunConfig limits what the invocation may spend. Nil means no limits.
+	RunConfig *RunConfig
+
+	mu              sync.Mutex
+	overlay         *stateOverlay // Set for branches, see NewBranch
+	pendingTransfer string
+	transferredTo   string
+	transferCount   int
+	escalated       bool
+	escalation      string
+	spending        *spending // Shared with branches, see Start
+}
+
+// Escalate asks the enclosing LoopAgent to stop after the running sub-agent
+// finishes. Tools and sub-agents call it through the invocation context.
+func (c *InvocationContext) Escalate(reason string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.escalated = true
+	c.escalation = reason
+}
+
+// TakeEscalation returns and clears the pending escalation, if any.
+func (c *InvocationContext) TakeEscalation() (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	reason, escalated := c.escalation, c.escalated
+	c.escalated, c.escalation = false, ""
+	return reason, escalated
+}
+
+// RequestTransfer asks the running agent to hand the conversation to the
+// named agent once its current tool calls have finished.
+func (c *InvocationContext) RequestTransfer(agentName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pendingTransfer = agentName
+}
+
+// TakeTransfer returns and clears the pending transfer request, if any.
+func (c *InvocationContext) TakeTransfer() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	name := c.pendingTransfer
+	c.pendingTransfer = ""
+	return name
+}
+
+// RecordTransfer notes that the conversation was handed to agentName and
+// returns the number of transfers made in this invocation.
+func (c *InvocationContext) RecordTransfer(agentName string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.transferredTo = agentName
+	c.transferCount++
+	return c.transferCount
+}
+
+// ReturnTransfer notes that the conversation went back to agentName after
+// the agent it was transferred to answered, without counting a transfer.
+func (c *InvocationContext) ReturnTransfer(agentName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.transferredTo = agentName
+}
+
+// TransferredTo returns the agent the conversation was last transferred to
+// during this invocation, or "" if no transfer happened.
+func (c *InvocationContext) TransferredTo() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.transferredTo
 }
 
 func WithInvocationContext(ctx context.Context, invCtx *InvocationContext) context.Context {
@@ -48,3 +128,39 @@ func SendInternalLog(ctx context.Context, format string, a ...any) {
 		sender("internal_log", map[string]string{"text": text})
 	}
 }
+
+// ToolConfirmationRequestEvent is the UI message type sent when a tool call is
+// waiting for the user's approval.
+const ToolConfirmationRequestEvent = "tool_confirmation_request"
+
+// PlanUpdateEvent is the UI message type sent with the agents.Plan whenever
+// a planning agent creates or updates its plan.
+const PlanUpdateEvent = "plan_update"
+
+type ToolConfirmationRequest struct {
+	ID        string         `json:"id"`
+	AgentName string         `json:"agentName"`
+	ToolName  string         `json:"toolName"`
+	Args      map[string]any `json:"args"`
+}
+
+type ToolConfirmation struct {
+	Approved bool
+	// Args, if set, replaces the arguments the model proposed.
+	Args map[string]any
+	// Reason optionally explains a rejection to the model.
+	Reason string
+}
+
+// Confirmer asks the user to approve a tool call, blocking until they answer
+// or ctx is done.
+type Confirmer func(ctx context.Context, req ToolConfirmationRequest) (ToolConfirmation, error)
+
+func WithConfirmer(ctx context.Context, confirmer Confirmer) context.Context {
+	return context.WithValue(ctx, confirmerKey, confirmer)
+}
+
+func GetConfirmer(ctx context.Context) (Confirmer, bool) {
+	confirmer, ok := ctx.Value(confirmerKey).(Confirmer)
+	return confirmer, ok && confirmer != nil
+}
@@ -6,22 +6,58 @@ import (
 
 	"github.com/KennethanCeyer/adk-go/agents/interfaces"
 	"github.com/KennethanCeyer/adk-go/agents/invocation"
-	"github.com/KennethanCeyer/adk-go/llmproviders"
 	modelstypes "github.com/KennethanCeyer/adk-go/models/types"
-	"github.com/KennethanCeyer/adk-go/tools"
+	"github.com/KennethanCeyer/adk-go/sessions"
 )
 
 type StopCondition func(latestResponse *modelstypes.Message) bool
 
+// LoopIteration is what a StateStopCondition sees after each iteration.
+type LoopIteration struct {
+	Number   int // 1-based
+	Response *modelstypes.Message
+	// State is a copy of the state visible to the loop, including the writes
+	// of this iteration's sub-agents.
+	State map[string]any
+	// Session is the full session, or nil outside a session.
+	Session *sessions.Session
+}
+
+type StateStopCondition func(it LoopIteration) bool
+
+// LoopExitReason tells why a LoopAgent stopped.
+type LoopExitReason string
+
+const (
+	LoopConditionMet  LoopExitReason = "condition_met"
+	LoopEscalated     LoopExitReason = "escalated"
+	LoopMaxIterations LoopExitReason = "max_iterations"
+)
+
+// LoopResult reports how a loop ended.
+type LoopResult struct {
+	Response  *modelstypes.Message
+	Iteration int // The 1-based iteration the loop ended in
+	Reason    LoopExitReason
+	// EscalatedBy and Escalation name the sub-agent that escalated and the
+	// reason it gave, if Reason is LoopEscalated.
+	EscalatedBy string
+	Escalation  string
+}
+
 type LoopAgent struct {
 	AgentName        string
 	AgentDescription string
-	SubAgents        []interfaces.LlmAgent
+	SubAgents        []interfaces.Agent
 	MaxIterations    int
 	StopWhen         StopCondition
+	StopWhenState    StateStopCondition
+	// ResultKey, when set, stores the LoopResult of each run in the session
+	// state as {"iteration", "reason", "escalated_by", "escalation"}.
+	ResultKey string
 }
 
-func NewLoopAgent(name, description string, subAgents []interfaces.LlmAgent, maxIterations int, stopWhen StopCondition) *LoopAgent {
+func NewLoopAgent(name, description string, subAgents []interfaces.Agent, maxIterations int, stopWhen StopCondition) *LoopAgent {
 	return &LoopAgent{
 		AgentName:        name,
 		AgentDescription: description,
@@ -31,25 +67,44 @@ func NewLoopAgent(name, description string, subAgents []interfaces.LlmAgent, max
 	}
 }
 
-func (a *LoopAgent) GetName() string                            { return a.AgentName }
-func (a *LoopAgent) GetDescription() string                     { return a.AgentDescription }
-func (a *LoopAgent) GetModelIdentifier() string                 { return "workflow-loop" }
-func (a *LoopAgent) GetSystemInstruction() *modelstypes.Message { return nil }
-func (a *LoopAgent) GetTools() []tools.Tool                     { return nil }
-func (a *LoopAgent) GetLLMProvider() llmproviders.LLMProvider   { return nil }
+func (a *LoopAgent) GetName() string                  { return a.AgentName }
+func (a *LoopAgent) GetDescription() string           { return a.AgentDescription }
+func (a *LoopAgent) GetSubAgents() []interfaces.Agent { return a.SubAgents }
 
 func (a *LoopAgent) Process(
 	ctx context.Context,
 	history []modelstypes.Message,
 	latestContent modelstypes.Message,
 ) (*modelstypes.Message, error) {
+	result, err := a.Run(ctx, history, latestContent)
+	if err != nil {
+		return nil, err
+	}
+	return result.Response, nil
+}
+
+// Run executes the loop like Process and also reports how it ended. The loop
+// stops after MaxIterations, when a stop condition holds after an iteration,
+// or as soon as a sub-agent or one of its tools escalates through the
+// invocation context.
+func (a *LoopAgent) Run(
+	ctx context.Context,
+	history []modelstypes.Message,
+	latestContent modelstypes.Message,
+) (*LoopResult, error) {
 	invocation.SendInternalLog(ctx, "Starting loop for agent '%s' (max %d iterations)...", a.GetName(), a.MaxIterations)
 
+	invCtx := invocation.FromContext(ctx)
+	// An escalation left over from before the loop started is not ours.
+	invCtx.TakeEscalation()
+
 	currentHistory := history
 	currentMessage := latestContent
-	var finalResponse *modelstypes.Message
+	result := &LoopResult{Reason: LoopMaxIterations}
 
+loop:
 	for i := 0; i < a.MaxIterations; i++ {
+		result.Iteration = i + 1
 		invocation.SendInternalLog(ctx, "Loop iteration %d/%d", i+1, a.MaxIterations)
 
 		for _, subAgent := range a.SubAgents {
@@ -62,13 +117,42 @@ func (a *LoopAgent) Process(
 				currentHistory = append(currentHistory, *response)
 				currentMessage = *response
 			}
-			finalResponse = response
+			result.Response = response
+
+			if reason, escalated := invCtx.TakeEscalation(); escalated {
+				result.Reason, result.EscalatedBy, result.Escalation = LoopEscalated, subAgent.GetName(), reason
+				invocation.SendInternalLog(ctx, "Sub-agent '%s' escalated on iteration %d: %s", subAgent.GetName(), i+1, reason)
+				break loop
+			}
 		}
 
-		if a.StopWhen != nil && a.StopWhen(finalResponse) {
+		if a.StopWhen != nil && a.StopWhen(result.Response) {
+			result.Reason = LoopConditionMet
 			invocation.SendInternalLog(ctx, "Loop stop condition met on iteration %d.", i+1)
 			break
 		}
+		if a.StopWhenState != nil && a.StopWhenState(LoopIteration{
+			Number:   i + 1,
+			Response: result.Response,
+			State:    invCtx.StateSnapshot(),
+			Session:  invCtx.Session,
+		}) {
+			result.Reason = LoopConditionMet
+			invocation.SendInternalLog(ctx, "Loop state condition met on iteration %d.", i+1)
+			break
+		}
+	}
+
+	if result.Reason == LoopMaxIterations {
+		invocation.SendInternalLog(ctx, "Loop reached the maximum of %d iterations.", a.MaxIterations)
+	}
+	if a.ResultKey != "" {
+		invCtx.SetState(a.ResultKey, map[string]any{
+			"iteration":    result.Iteration,
+			"reason":       string(result.Reason),
+			"escalated_by": result.EscalatedBy,
+			"escalation":   result.Escalation,
+		})
 	}
-	return finalResponse, nil
+	return result, nil
 }
@@ -0,0 +1,339 @@
+package agents
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/KennethanCeyer/adk-go/agents/interfaces"
+	"github.com/KennethanCeyer/adk-go/agents/invocation"
+	modelstypes "github.com/KennethanCeyer/adk-go/models/types"
+)
+
+const defaultMapConcurrency = 4
+
+// MapItemResult is the outcome of running the mapper on one item.
+type MapItemResult struct {
+	Index    int // 0-based position of the item in the list
+	Item     any
+	Response *modelstypes.Message
+	Err      error // Set if every attempt failed
+	Attempts int
+}
+
+// MapResult reports how a MapAgent run went.
+type MapResult struct {
+	Response *modelstypes.Message // The reducer's response
+	Items    []MapItemResult      // In list order
+	Failed   int
+}
+
+// Reducer combines the results of a MapAgent, given in list order and
+// including the failed items, into its response to request.
+type Reducer func(ctx context.Context, request modelstypes.Message, results []MapItemResult) (*modelstypes.Message, error)
+
+// MapAgent applies Mapper to every item of a list, at most MaxConcurrency
+// items at a time, and combines the results with a reducer. The list is read
+// from the session state under ItemsKey, or, if ItemsKey is empty, from the
+// message the agent receives, which must be a JSON array or an object with an
+// "items" array, such as the structured output of a previous agent.
+//
+// Each item runs in its own branch with a private copy of the history, and a
+// failed item is retried up to MaxRetries times. Items run in isolation:
+// the state they write is discarded, and only their responses reach the
+// reducer. The run fails only if every item fails; otherwise the failures
+// are passed on to the reducer and reported in the MapResult.
+type MapAgent struct {
+	AgentName        string
+	AgentDescription string
+	Mapper           interfaces.Agent
+	ItemsKey         string
+	MaxConcurrency   int           // Defaults to 4
+	MaxRetries       int           // Retries per item after the first attempt
+	RetryDelay       time.Duration // Wait before the first retry, doubled for each further one
+	// FormatItem builds the mapper's input for an item. By default strings
+	// are sent as they are and other values as JSON.
+	FormatItem func(index int, item any) modelstypes.Message
+	// Reducer combines the results. When nil, ReducerAgent summarizes them if
+	// it is set, and otherwise ListResults returns them as they are.
+	Reducer      Reducer
+	ReducerAgent interfaces.Agent
+	// ResultKey, when set, stores a report of each run in the session state
+	// as {"total", "succeeded", "failed", "failures"}.
+	ResultKey string
+}
+
+func NewMapAgent(name, description string, mapper interfaces.Agent, itemsKey string) *MapAgent {
+	return &MapAgent{
+		AgentName:        name,
+		AgentDescription: description,
+		Mapper:           mapper,
+		ItemsKey:         itemsKey,
+	}
+}
+
+func (a *MapAgent) GetName() string        { return a.AgentName }
+func (a *MapAgent) GetDescription() string { return a.AgentDescription }
+
+func (a *MapAgent) GetSubAgents() []interfaces.Agent {
+	subAgents := []interfaces.Agent{a.Mapper}
+	if a.ReducerAgent != nil {
+		subAgents = append(subAgents, a.ReducerAgent)
+	}
+	return subAgents
+}
+
+func (a *MapAgent) Process(
+	ctx context.Context,
+	history []modelstypes.Message,
+	latestContent modelstypes.Message,
+) (*modelstypes.Message, error) {
+	result, err := a.Run(ctx, history, latestContent)
+	if err != nil {
+		return nil, err
+	}
+	return result.Response, nil
+}
+
+// Run maps and reduces like Process and also returns the result of every
+// item.
+func (a *MapAgent) Run(
+	ctx context.Context,
+	history []modelstypes.Message,
+	latestContent modelstypes.Message,
+) (*MapResult, error) {
+	if a.Mapper == nil {
+		return nil, fmt.Errorf("map agent '%s' has no mapper", a.AgentName)
+	}
+	invCtx := invocation.FromContext(ctx)
+	items, err := a.items(invCtx, latestContent)
+	if err != nil {
+		return nil, err
+	}
+	concurrency := a.MaxConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultMapConcurrency
+	}
+	invocation.SendInternalLog(ctx, "Mapping '%s' over %d items (%d at a time)...", a.Mapper.GetName(), len(items), concurrency)
+
+	// The items share the invocation's budget, so the first item that runs
+	// out of it stops the others instead of letting each one find out on its
+	// own.
+	mapCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var budgetOnce sync.Once
+	var budgetErr error
+
+	result := &MapResult{Items: make([]MapItemResult, len(items))}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, item := range items {
+		// Taking the semaphore here rather than in the goroutine keeps at most
+		// concurrency goroutines alive, however long the list is.
+		select {
+		case sem <- struct{}{}:
+		case <-mapCtx.Done():
+		}
+		if mapCtx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(i int, item any) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			itemResult := a.mapItem(mapCtx, invCtx, history, i, item)
+			result.Items[i] = itemResult
+			if budgetExceeded(itemResult.Err) {
+				budgetOnce.Do(func() {
+					budgetErr = fmt.Errorf("item %d failed: %w", i+1, itemResult.Err)
+					cancel()
+				})
+			}
+		}(i, item)
+	}
+	// Items still running after a cancellation return as soon as they see it.
+	wg.Wait()
+	if budgetErr != nil {
+		return nil, budgetErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var firstErr error
+	for _, item := range result.Items {
+		if item.Err != nil {
+			result.Failed++
+			if firstErr == nil {
+				firstErr = fmt.Errorf("item %d failed: %w", item.Index+1, item.Err)
+			}
+		}
+	}
+	if a.ResultKey != "" {
+		invCtx.SetState(a.ResultKey, a.report(result))
+	}
+	if len(items) > 0 && result.Failed == len(items) {
+		return nil, fmt.Errorf("map agent '%s': all %d items failed; first error: %w", a.AgentName, len(items), firstErr)
+	}
+	if result.Failed > 0 {
+		invocation.SendInternalLog(ctx, "%d of %d items failed; reducing the rest.", result.Failed, len(items))
+	}
+
+	reduce := a.Reducer
+	if reduce == nil {
+		if a.ReducerAgent != nil {
+			reduce = AgentReducer(a.ReducerAgent, history)
+		} else {
+			reduce = ListResults
+		}
+	}
+	invocation.SendInternalLog(ctx, "Reducing the results of %d items...", len(items)-result.Failed)
+	result.Response, err = reduce(ctx, latestContent, result.Items)
+	if err != nil {
+		return nil, fmt.Errorf("map agent '%s' failed to reduce the results: %w", a.AgentName, err)
+	}
+	return result, nil
+}
+
+// items reads the list to map over.
+func (a *MapAgent) items(invCtx *invocation.InvocationContext, latestContent modelstypes.Message) ([]any, error) {
+	var value any
+	source := "the received message"
+	if a.ItemsKey != "" {
+		source = fmt.Sprintf("state key '%s'", a.ItemsKey)
+		stored, ok := invCtx.GetState(a.ItemsKey)
+		if !ok {
+			return nil, fmt.Errorf("map agent '%s': %s is not set", a.AgentName, source)
+		}
+		value = stored
+		if text, isText := stored.(string); isText {
+			if parsed, ok := parseStructuredOutput(text); ok {
+				value = parsed
+			}
+		}
+	} else {
+		parsed, ok := parseStructuredOutput(messageText(latestContent))
+		if !ok {
+			return nil, fmt.Errorf("map agent '%s': expected %s to be a JSON array of items", a.AgentName, source)
+		}
+		value = parsed
+	}
+
+	if object, ok := value.(map[string]any); ok {
+		value = object["items"]
+	}
+	// Lists of any element type are accepted, e.g. []string set by Go code.
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("map agent '%s': cannot read the items from %s: %w", a.AgentName, source, err)
+	}
+	var items []any
+	if err := json.Unmarshal(data, &items); err != nil || items == nil {
+		return nil, fmt.Errorf("map agent '%s': expected %s to hold a list of items, got %T", a.AgentName, source, value)
+	}
+	return items, nil
+}
+
+// mapItem runs the mapper on one item, retrying failed attempts.
+func (a *MapAgent) mapItem(ctx context.Context, invCtx *invocation.InvocationContext, history []modelstypes.Message, index int, item any) MapItemResult {
+	result := MapItemResult{Index: index, Item: item}
+	input := a.formatItem(index, item)
+	delay := a.RetryDelay
+	for attempt := 1; attempt <= a.MaxRetries+1; attempt++ {
+		result.Attempts = attempt
+		branch := invCtx.NewBranch(fmt.Sprintf("%s.%d", a.AgentName, index+1))
+		branchHistory := make([]modelstypes.Message, len(history))
+		copy(branchHistory, history)
+		result.Response, result.Err = a.Mapper.Process(invocation.WithInvocationContext(ctx, branch), branchHistory, input)
+		if result.Err == nil || ctx.Err() != nil || attempt > a.MaxRetries || budgetExceeded(result.Err) {
+			break
+		}
+		invocation.SendInternalLog(ctx, "  - Item %d failed (attempt %d/%d): %v", index+1, attempt, a.MaxRetries+1, result.Err)
+		if delay > 0 {
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return result
+			}
+			delay *= 2
+		}
+	}
+	return result
+}
+
+func (a *MapAgent) formatItem(index int, item any) modelstypes.Message {
+	if a.FormatItem != nil {
+		return a.FormatItem(index, item)
+	}
+	text := formatStateValue(item)
+	return modelstypes.Message{Role: "user", Parts: []modelstypes.Part{{Text: &text}}}
+}
+
+func (a *MapAgent) report(result *MapResult) map[string]any {
+	failures := []any{}
+	for _, item := range result.Items {
+		if item.Err != nil {
+			failures = append(failures, map[string]any{
+				"index":    item.Index,
+				"item":     item.Item,
+				"error":    item.Err.Error(),
+				"attempts": item.Attempts,
+			})
+		}
+	}
+	return map[string]any{
+		"total":     len(result.Items),
+		"succeeded": len(result.Items) - result.Failed,
+		"failed":    result.Failed,
+		"failures":  failures,
+	}
+}
+
+// ListResults is a Reducer that makes no model call. It responds with a JSON
+// array holding, for each item, the item and either its response text or
+// the error it failed with.
+func ListResults(_ context.Context, _ modelstypes.Message, results []MapItemResult) (*modelstypes.Message, error) {
+	list := make([]map[string]any, len(results))
+	for i, result := range results {
+		entry := map[string]any{"item": result.Item}
+		if result.Err != nil {
+			entry["error"] = result.Err.Error()
+		} else if result.Response != nil {
+			entry["response"] = messageText(*result.Response)
+		}
+		list[i] = entry
+	}
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	text := string(data)
+	return &modelstypes.Message{Role: "model", Parts: []modelstypes.Part{{Text: &text}}}, nil
+}
+
+// AgentReducer returns a Reducer that gives agent the original request and
+// the results of all items, noting the ones that failed, and responds with
+// the agent's answer.
+func AgentReducer(agent interfaces.Agent, history []modelstypes.Message) Reducer {
+	return func(ctx context.Context, request modelstypes.Message, results []MapItemResult) (*modelstypes.Message, error) {
+		var sb strings.Builder
+		if text := messageText(request); text != "" {
+			fmt.Fprintf(&sb, "Original request:\n%s\n\n", text)
+		}
+		fmt.Fprintf(&sb, "Results for %d items:", len(results))
+		for _, result := range results {
+			fmt.Fprintf(&sb, "\n\n[%d] %s\n", result.Index+1, formatStateValue(result.Item))
+			switch {
+			case result.Err != nil:
+				fmt.Fprintf(&sb, "(failed: %v)", result.Err)
+			case result.Response != nil:
+				sb.WriteString(messageText(*result.Response))
+			}
+		}
+		text := sb.String()
+		return agent.Process(ctx, history, modelstypes.Message{Role: "user", Parts: []modelstypes.Part{{Text: &text}}})
+	}
+}
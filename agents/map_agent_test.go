@@ -0,0 +1,86 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/KennethanCeyer/adk-go/agents/invocation"
+	modelstypes "github.com/KennethanCeyer/adk-go/models/types"
+)
+
+func itemsMessage(n int) modelstypes.Message {
+	items := make([]string, n)
+	for i := range items {
+		items[i] = `"x"`
+	}
+	text := "[" + strings.Join(items, ",") + "]"
+	return modelstypes.Message{Role: "user", Parts: []modelstypes.Part{{Text: &text}}}
+}
+
+func TestMapAgentStopsOnBudgetError(t *testing.T) {
+	var attempts atomic.Int32
+	var started sync.WaitGroup
+	started.Add(3)
+	mapper := NewFuncAgent("mapper", "", func(ctx context.Context, _ []modelstypes.Message, latest modelstypes.Message) (*modelstypes.Message, error) {
+		attempts.Add(1)
+		if invocation.FromContext(ctx).Branch == "map.1" {
+			started.Wait() // Fail once the other items are running.
+			return nil, &invocation.BudgetExceededError{Budget: invocation.BudgetLLMCalls, Agent: "mapper", Limit: 1, Used: 1}
+		}
+		started.Done()
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	agent := NewMapAgent("map", "", mapper, "")
+	agent.MaxConcurrency = 4
+	agent.MaxRetries = 3
+	agent.RetryDelay = time.Hour
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := agent.Run(context.Background(), nil, itemsMessage(20))
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		var budgetErr *invocation.BudgetExceededError
+		if !errors.As(err, &budgetErr) {
+			t.Fatalf("err = %v, want a *BudgetExceededError", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not stop after the budget error")
+	}
+	if got := attempts.Load(); got != 4 {
+		t.Errorf("mapper ran %d times, want 4: the running items once each and no new items", got)
+	}
+}
+
+func TestMapAgentBoundsGoroutines(t *testing.T) {
+	baseline := runtime.NumGoroutine()
+	var peak atomic.Int32
+	mapper := NewFuncAgent("mapper", "", TextFunc(func(_ context.Context, text string) (string, error) {
+		if n := int32(runtime.NumGoroutine() - baseline); n > peak.Load() {
+			peak.Store(n)
+		}
+		return text, nil
+	}))
+	agent := NewMapAgent("map", "", mapper, "")
+	agent.MaxConcurrency = 2
+
+	result, err := agent.Run(context.Background(), nil, itemsMessage(500))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result.Failed != 0 || len(result.Items) != 500 {
+		t.Errorf("failed %d of %d items", result.Failed, len(result.Items))
+	}
+	if got := peak.Load(); got > 10 {
+		t.Errorf("%d goroutines were alive while mapping, want about MaxConcurrency", got)
+	}
+}
@@ -2,6 +2,8 @@ package agents
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
@@ -10,19 +12,67 @@ import (
 	"github.com/KennethanCeyer/adk-go/agents/invocation"
 	"github.com/KennethanCeyer/adk-go/llmproviders"
 	modelstypes "github.com/KennethanCeyer/adk-go/models/types"
-	"github.com/KennethanCeyer/adk-go/tools"
 )
 
+// ErrorPolicy decides how a ParallelAgent reacts to failing sub-agents.
+type ErrorPolicy int
+
+const (
+	// FailFast cancels the remaining sub-agents as soon as one fails and
+	// returns its error.
+	FailFast ErrorPolicy = iota
+	// BestEffort waits for every sub-agent and synthesizes the successful
+	// results together with the errors of the failed ones. It only fails if
+	// every sub-agent fails.
+	BestEffort
+	// Quorum finishes as soon as ParallelAgent.Quorum sub-agents have
+	// succeeded, cancelling the rest, and fails once that is out of reach.
+	Quorum
+)
+
+func (p ErrorPolicy) String() string {
+	switch p {
+	case FailFast:
+		return "fail-fast"
+	case BestEffort:
+		return "best-effort"
+	case Quorum:
+		return "quorum"
+	default:
+		return fmt.Sprintf("ErrorPolicy(%d)", int(p))
+	}
+}
+
+// errCancelledAfterQuorum marks the sub-agents that were still running when
+// the quorum was reached.
+var errCancelledAfterQuorum = errors.New("cancelled after the quorum was reached")
+
+// ParallelResult is the outcome of one sub-agent of a ParallelAgent.
+type ParallelResult struct {
+	AgentName string
+	Response  *modelstypes.Message // Nil if Err is set
+	Err       error
+}
+
+// Synthesizer combines the results of a ParallelAgent's sub-agents, given in
+// the order of SubAgents, into its response to request.
+type Synthesizer func(ctx context.Context, request modelstypes.Message, results []ParallelResult) (*modelstypes.Message, error)
+
 type ParallelAgent struct {
 	AgentName         string
 	AgentDescription  string
-	SubAgents         []interfaces.LlmAgent
+	SubAgents         []interfaces.Agent
 	Provider          llmproviders.LLMProvider
 	ModelID           string
 	SysInstruction    *modelstypes.Message
+	ErrorPolicy       ErrorPolicy
+	Quorum            int // Successes required by the Quorum policy
+	// Synthesizer combines the results. When nil, the results are summarized
+	// by Provider if it is set, or returned by KeyedResults otherwise.
+	Synthesizer Synthesizer
 }
 
-func NewParallelAgent(name, description, modelID string, systemInstruction *modelstypes.Message, provider llmproviders.LLMProvider, subAgents []interfaces.LlmAgent) *ParallelAgent {
+func NewParallelAgent(name, description, modelID string, systemInstruction *modelstypes.Message, provider llmproviders.LLMProvider, subAgents []interfaces.Agent) *ParallelAgent {
 	return &ParallelAgent{
 		AgentName:         name,
 		AgentDescription:  description,
@@ -33,59 +83,180 @@ func NewParallelAgent(name, description, modelID string, systemInstruction *mode
 	}
 }
 
-func (a *ParallelAgent) GetName() string                            { return a.AgentName }
-func (a *ParallelAgent) GetDescription() string                     { return a.AgentDescription }
-func (a *ParallelAgent) GetModelIdentifier() string                 { return a.ModelID }
-func (a *ParallelAgent) GetSystemInstruction() *modelstypes.Message { return a.SysInstruction }
-func (a *ParallelAgent) GetTools() []tools.Tool                     { return nil }
-func (a *ParallelAgent) GetLLMProvider() llmproviders.LLMProvider   { return a.Provider }
+func (a *ParallelAgent) GetName() string                  { return a.AgentName }
+func (a *ParallelAgent) GetDescription() string           { return a.AgentDescription }
+func (a *ParallelAgent) GetSubAgents() []interfaces.Agent { return a.SubAgents }
 
 func (a *ParallelAgent) Process(
 	ctx context.Context,
 	history []modelstypes.Message,
 	latestContent modelstypes.Message,
 ) (*modelstypes.Message, error) {
+	required := len(a.SubAgents)
+	switch a.ErrorPolicy {
+	case BestEffort:
+		required = 1
+	case Quorum:
+		if a.Quorum <= 0 || a.Quorum > len(a.SubAgents) {
+			return nil, fmt.Errorf("parallel agent '%s': quorum must be between 1 and %d, got %d", a.AgentName, len(a.SubAgents), a.Quorum)
+		}
+		required = a.Quorum
+	}
+
+	branchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type outcome struct {
+		index    int
+		response *modelstypes.Message
+		err      error
+	}
 	var wg sync.WaitGroup
-	resultsChan := make(chan *modelstypes.Message, len(a.SubAgents))
-	errChan := make(chan error, len(a.SubAgents))
+	outcomes := make(chan outcome, len(a.SubAgents))
 
-	invocation.SendInternalLog(ctx, "Starting parallel execution for %d sub-agents...", len(a.SubAgents))
-	for _, subAgent := range a.SubAgents {
+	// Every sub-agent runs in its own branch with a private copy of the
+	// history and its own state overlay, merged once all branches are done.
+	invCtx := invocation.FromContext(ctx)
+	branches := make([]*invocation.InvocationContext, len(a.SubAgents))
+
+	invocation.SendInternalLog(ctx, "Starting parallel execution for %d sub-agents (%s)...", len(a.SubAgents), a.ErrorPolicy)
+	for i, subAgent := range a.SubAgents {
+		branches[i] = invCtx.NewBranch(a.AgentName + "." + subAgent.GetName())
 		wg.Add(1)
-		go func(sa interfaces.LlmAgent) {
+		go func(i int, sa interfaces.Agent) {
 			defer wg.Done()
-			invocation.SendInternalLog(ctx, "Running sub-agent in parallel: %s", sa.GetName())
-			response, err := sa.Process(ctx, history, latestContent)
-			if err != nil {
-				errChan <- fmt.Errorf("sub-agent '%s' failed: %w", sa.GetName(), err)
-				return
-			}
-			resultsChan <- response
-		}(subAgent)
+			invocation.SendInternalLog(ctx, "Running sub-agent in parallel: %s (branch %s)", sa.GetName(), branches[i].Branch)
+			branchHistory := make([]modelstypes.Message, len(history))
+			copy(branchHistory, history)
+			response, err := sa.Process(invocation.WithInvocationContext(branchCtx, branches[i]), branchHistory, latestContent)
+			outcomes <- outcome{index: i, response: response, err: err}
+		}(i, subAgent)
 	}
 
+	results := make([]ParallelResult, len(a.SubAgents))
+	completed := make([]bool, len(a.SubAgents))
+	var succeeded, failed int
+	var firstErr, budgetErr error
+	for received := 0; received < len(a.SubAgents); received++ {
+		o := <-outcomes
+		completed[o.index] = true
+		results[o.index] = ParallelResult{AgentName: a.SubAgents[o.index].GetName(), Response: o.response, Err: o.err}
+		if o.err != nil {
+			failed++
+			if firstErr == nil {
+				firstErr = fmt.Errorf("sub-agent '%s' failed: %w", a.SubAgents[o.index].GetName(), o.err)
+			}
+			invocation.SendInternalLog(ctx, "  - Sub-agent '%s' failed: %v", a.SubAgents[o.index].GetName(), o.err)
+			if budgetExceeded(o.err) {
+				budgetErr = fmt.Errorf("sub-agent '%s' failed: %w", a.SubAgents[o.index].GetName(), o.err)
+				break // The other sub-agents share the budget.
+			}
+		} else {
+			succeeded++
+		}
+		if len(a.SubAgents)-failed < required {
+			break // Cannot succeed anymore.
+		}
+		if succeeded >= required && a.ErrorPolicy != BestEffort {
+			break
+		}
+	}
+	// Stop the sub-agents that are still running and wait for them to return.
+	cancel()
 	wg.Wait()
-	close(resultsChan)
-	close(errChan)
 
-	if len(errChan) > 0 {
-		return nil, <-errChan
+	if budgetErr != nil {
+		return nil, budgetErr
 	}
+	if succeeded < required {
+		switch a.ErrorPolicy {
+		case FailFast:
+			return nil, firstErr
+		case BestEffort:
+			return nil, fmt.Errorf("all %d sub-agents failed; first error: %w", len(a.SubAgents), firstErr)
+		default:
+			return nil, fmt.Errorf("quorum of %d not reached: %d of %d sub-agents failed; first error: %w", required, failed, len(a.SubAgents), firstErr)
+		}
+	}
+	for i := range results {
+		if !completed[i] {
+			results[i] = ParallelResult{AgentName: a.SubAgents[i].GetName(), Err: errCancelledAfterQuorum}
+		}
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	// Only the state written by successful branches is kept.
+	var succeededBranches []*invocation.InvocationContext
+	for i, result := range results {
+		if result.Err == nil {
+			succeededBranches = append(succeededBranches, branches[i])
+		}
+	}
+	if err := invCtx.MergeBranches(succeededBranches...); err != nil {
+		return nil, fmt.Errorf("parallel agent '%s': %w", a.AgentName, err)
+	}
+
+	synthesize := a.Synthesizer
+	if synthesize == nil {
+		if a.Provider != nil {
+			synthesize = LLMSynthesizer(a.Provider, a.ModelID, a.SysInstruction)
+		} else {
+			synthesize = KeyedResults
+		}
+	}
+	invocation.SendInternalLog(ctx, "Synthesizing results from %d parallel sub-agents...", succeeded)
+	return synthesize(ctx, latestContent, results)
+}
 
-	var subAgentResults []string
-	for result := range resultsChan {
-		if result != nil {
-			for _, part := range result.Parts {
-				if part.Text != nil {
-					subAgentResults = append(subAgentResults, *part.Text)
+// LLMSynthesizer returns a Synthesizer that asks the model to summarize the
+// results for the user. Failed sub-agents are mentioned in the prompt so the
+// model can point out what is missing.
+func LLMSynthesizer(provider llmproviders.LLMProvider, modelID string, systemInstruction *modelstypes.Message) Synthesizer {
+	return func(ctx context.Context, request modelstypes.Message, results []ParallelResult) (*modelstypes.Message, error) {
+		var subAgentResults []string
+		for _, result := range results {
+			if result.Err != nil {
+				subAgentResults = append(subAgentResults, fmt.Sprintf("(%s could not provide a result: %v)", result.AgentName, result.Err))
+				continue
+			}
+			if result.Response != nil {
+				for _, part := range result.Response.Parts {
+					if part.Text != nil {
+						subAgentResults = append(subAgentResults, *part.Text)
+					}
 				}
 			}
 		}
-	}
 
-	invocation.SendInternalLog(ctx, "Synthesizing results from %d parallel sub-agents...", len(a.SubAgents))
-	synthesisPromptText := fmt.Sprintf("The following information was gathered concurrently:\n\n---\n%s\n---\n\nBased on this information, provide a comprehensive summary to the user.", strings.Join(subAgentResults, "\n---\n"))
-	synthesisMessage := modelstypes.Message{Role: "user", Parts: []modelstypes.Part{{Text: &synthesisPromptText}}}
+		synthesisPromptText := fmt.Sprintf("The following information was gathered concurrently:\n\n---\n%s\n---\n\nBased on this information, provide a comprehensive summary to the user.", strings.Join(subAgentResults, "\n---\n"))
+		synthesisMessage := modelstypes.Message{Role: "user", Parts: []modelstypes.Part{{Text: &synthesisPromptText}}}
 
-	return a.Provider.GenerateContent(ctx, a.ModelID, a.SysInstruction, nil, nil, synthesisMessage)
+		return generate(ctx, "synthesizer", provider, modelID, systemInstruction, synthesisMessage)
+	}
+}
+
+// KeyedResults is a Synthesizer that makes no model call. It responds with a
+// JSON object mapping each sub-agent's name to its response text, or to
+// {"error": "..."} if it failed.
+func KeyedResults(_ context.Context, _ modelstypes.Message, results []ParallelResult) (*modelstypes.Message, error) {
+	keyed := make(map[string]any, len(results))
+	for _, result := range results {
+		if result.Err != nil {
+			keyed[result.AgentName] = map[string]any{"error": result.Err.Error()}
+			continue
+		}
+		text := ""
+		if result.Response != nil {
+			text = messageText(*result.Response)
+		}
+		keyed[result.AgentName] = text
+	}
+	data, err := json.MarshalIndent(keyed, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode parallel results: %w", err)
+	}
+	text := string(data)
+	return &modelstypes.Message{Role: "model", Parts: []modelstypes.Part{{Text: &text}}}, nil
 }
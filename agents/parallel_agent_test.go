@@ -0,0 +1,42 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/KennethanCeyer/adk-go/agents/interfaces"
+	"github.com/KennethanCeyer/adk-go/agents/invocation"
+	modelstypes "github.com/KennethanCeyer/adk-go/models/types"
+	"github.com/KennethanCeyer/adk-go/sessions"
+)
+
+func TestParallelAgentDropsStateOfFailedBranches(t *testing.T) {
+	writer := func(key string, fail bool) AgentFunc {
+		return func(ctx context.Context, _ []modelstypes.Message, _ modelstypes.Message) (*modelstypes.Message, error) {
+			invocation.FromContext(ctx).SetState(key, "written")
+			if fail {
+				return nil, errors.New("boom")
+			}
+			text := key
+			return &modelstypes.Message{Role: "model", Parts: []modelstypes.Part{{Text: &text}}}, nil
+		}
+	}
+	agent := NewParallelAgent("fan", "", "", nil, nil, []interfaces.Agent{
+		NewFuncAgent("good", "", writer("good_key", false)),
+		NewFuncAgent("bad", "", writer("bad_key", true)),
+	})
+	agent.ErrorPolicy = BestEffort
+
+	sess := &sessions.Session{ID: "s"}
+	ctx := invocation.WithInvocationContext(context.Background(), &invocation.InvocationContext{Session: sess})
+	if _, err := agent.Process(ctx, nil, modelstypes.Message{Role: "user"}); err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+	if v, _ := sess.GetState("good_key"); v != "written" {
+		t.Errorf("good_key = %v, want the successful branch's write", v)
+	}
+	if v, ok := sess.GetState("bad_key"); ok {
+		t.Errorf("bad_key = %v, want the failed branch's write dropped", v)
+	}
+}
@@ -0,0 +1,349 @@
+package agents
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/KennethanCeyer/adk-go/agents/invocation"
+	modelstypes "github.com/KennethanCeyer/adk-go/models/types"
+	"github.com/KennethanCeyer/adk-go/tools"
+)
+
+// Planner guides how an LLM agent works through a task. The agent consults
+// it before every model call.
+type Planner interface {
+	// Instruction returns text appended to the agent's system instruction,
+	// such as how to plan and the current state of the plan.
+	Instruction(ctx ReadonlyContext) (string, error)
+	// Tools returns the tools offered to the model for the next call, given
+	// the agent's own tools.
+	Tools(ctx ReadonlyContext, agentTools []tools.Tool) []tools.Tool
+}
+
+type PlanStepStatus string
+
+const (
+	PlanStepPending    PlanStepStatus = "pending"
+	PlanStepInProgress PlanStepStatus = "in_progress"
+	PlanStepDone       PlanStepStatus = "done"
+	PlanStepSkipped    PlanStepStatus = "skipped"
+)
+
+var planStepStatuses = []string{
+	string(PlanStepPending), string(PlanStepInProgress), string(PlanStepDone), string(PlanStepSkipped),
+}
+
+type PlanStep struct {
+	Title  string         `json:"title"`
+	Status PlanStepStatus `json:"status"`
+	Note   string         `json:"note,omitempty"` // What the step found or why it was skipped
+}
+
+// Plan is the numbered plan an agent made for one turn.
+type Plan struct {
+	InvocationID string     `json:"invocationId"`
+	AgentName    string     `json:"agentName"`
+	Goal         string     `json:"goal,omitempty"`
+	Steps        []PlanStep `json:"steps"`
+	Revision     int        `json:"revision"` // Incremented each time the steps are replaced
+}
+
+// Finished reports whether every step is done or skipped.
+func (p *Plan) Finished() bool {
+	for _, step := range p.Steps {
+		if step.Status != PlanStepDone && step.Status != PlanStepSkipped {
+			return false
+		}
+	}
+	return true
+}
+
+// Checklist renders the plan as a numbered checklist.
+func (p *Plan) Checklist() string {
+	var sb strings.Builder
+	if p.Goal != "" {
+		fmt.Fprintf(&sb, "Goal: %s\n", p.Goal)
+	}
+	for i, step := range p.Steps {
+		mark := " "
+		switch step.Status {
+		case PlanStepDone:
+			mark = "x"
+		case PlanStepInProgress:
+			mark = "~"
+		case PlanStepSkipped:
+			mark = "-"
+		}
+		fmt.Fprintf(&sb, "%d. [%s] %s", i+1, mark, step.Title)
+		if step.Note != "" {
+			fmt.Fprintf(&sb, " (%s)", step.Note)
+		}
+		sb.WriteString("\n")
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// PlanFromState decodes a plan stored in the session state.
+func PlanFromState(value any) (*Plan, bool) {
+	if value == nil {
+		return nil, false
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, false
+	}
+	var plan Plan
+	if err := json.Unmarshal(data, &plan); err != nil || len(plan.Steps) == 0 {
+		return nil, false
+	}
+	return &plan, true
+}
+
+// planToState converts the plan into plain JSON values, so that the session
+// state reads the same before and after it is saved to disk.
+func planToState(plan *Plan) any {
+	data, _ := json.Marshal(plan)
+	var value map[string]any
+	_ = json.Unmarshal(data, &value)
+	return value
+}
+
+const (
+	defaultPlanStateKey = "plan"
+	updatePlanToolName  = "update_plan"
+)
+
+// PlanActPlanner makes the agent plan before it acts. Until a plan exists
+// for the current turn, the model is only offered the update_plan tool and
+// asked to write down the numbered steps of the task. Afterwards it sees the
+// plan as a checklist in its instruction, gets its tools back, and works
+// through the steps, marking each one done or revising the remaining ones
+// with update_plan. Requests that need no tools can still be answered
+// directly.
+//
+// The plan is stored in the session state under StateKey, and every change
+// is sent to the UI as an invocation.PlanUpdateEvent.
+type PlanActPlanner struct {
+	StateKey string // Defaults to "plan"
+
+	mu sync.Mutex // Serializes plan updates from parallel tool calls
+}
+
+func NewPlanActPlanner() *PlanActPlanner {
+	return &PlanActPlanner{}
+}
+
+func (p *PlanActPlanner) stateKey() string {
+	if p.StateKey != "" {
+		return p.StateKey
+	}
+	return defaultPlanStateKey
+}
+
+// currentPlan returns the plan the agent made in this invocation, if any.
+func (p *PlanActPlanner) currentPlan(invCtx *invocation.InvocationContext, agentName string) (*Plan, bool) {
+	value, _ := invCtx.GetState(p.stateKey())
+	plan, ok := PlanFromState(value)
+	if !ok || plan.InvocationID != invCtx.ID || plan.AgentName != agentName {
+		return nil, false
+	}
+	return plan, true
+}
+
+func (p *PlanActPlanner) Instruction(ctx ReadonlyContext) (string, error) {
+	plan, ok := p.currentPlan(ctx.invCtx, ctx.AgentName)
+	if !ok {
+		return "Before you act on a request that takes more than one step, make a plan: call update_plan " +
+			"with the numbered steps you will take, in order, and nothing else. Your other tools become " +
+			"available once the plan exists. If the request needs no tools, answer it directly.", nil
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "You are working through this plan:\n\n%s\n\n", plan.Checklist())
+	if plan.Finished() {
+		sb.WriteString("Every step is done or skipped. Give the user your final answer based on the results.")
+	} else {
+		sb.WriteString("Work on the first step that is not done. When a step is finished, call update_plan to " +
+			"mark it done with a short note on the result; you may do so in the same response as the tool " +
+			"calls for the next step. If what you learn changes what needs to be done, revise the remaining " +
+			"steps by calling update_plan with the full list of steps. Give your final answer only when " +
+			"every step is done or skipped.")
+	}
+	return sb.String(), nil
+}
+
+func (p *PlanActPlanner) Tools(ctx ReadonlyContext, agentTools []tools.Tool) []tools.Tool {
+	tool := &updatePlanTool{planner: p, agentName: ctx.AgentName}
+	if _, ok := p.currentPlan(ctx.invCtx, ctx.AgentName); !ok {
+		return []tools.Tool{tool}
+	}
+	return append(append([]tools.Tool{}, agentTools...), tool)
+}
+
+// updatePlanTool creates, revises and checks off the steps of the plan.
+type updatePlanTool struct {
+	planner   *PlanActPlanner
+	agentName string
+}
+
+func (t *updatePlanTool) Name() string { return updatePlanToolName }
+
+func (t *updatePlanTool) Description() string {
+	return "Creates or updates your plan for the current request. Pass 'steps' with the full list of steps to " +
+		"create the plan or revise it, or pass 'step' with its new 'status' to update a single step."
+}
+
+func (t *updatePlanTool) Parameters() any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"goal": map[string]any{
+				"type":        "string",
+				"description": "What the plan achieves, in one sentence.",
+			},
+			"steps": map[string]any{
+				"type":        "array",
+				"description": "The full, ordered list of steps. Replaces the current steps.",
+				"items": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"title":  map[string]any{"type": "string", "description": "What the step does."},
+						"status": map[string]any{"type": "string", "enum": planStepStatuses, "description": "Defaults to pending."},
+						"note":   map[string]any{"type": "string", "description": "The result of the step, or why it was skipped."},
+					},
+					"required": []string{"title"},
+				},
+			},
+			"step": map[string]any{
+				"type":        "integer",
+				"description": "The 1-based number of a single step to update.",
+			},
+			"status": map[string]any{
+				"type":        "string",
+				"enum":        planStepStatuses,
+				"description": "The new status of 'step'.",
+			},
+			"note": map[string]any{
+				"type":        "string",
+				"description": "The result of 'step', or why it was skipped.",
+			},
+		},
+	}
+}
+
+func (t *updatePlanTool) Execute(ctx context.Context, args any) (any, error) {
+	argsMap, ok := args.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("invalid arguments format: expected map[string]any, got %T", args)
+	}
+	invCtx := invocation.FromContext(ctx)
+
+	t.planner.mu.Lock()
+	defer t.planner.mu.Unlock()
+
+	plan, exists := t.planner.currentPlan(invCtx, t.agentName)
+	if !exists {
+		plan = &Plan{InvocationID: invCtx.ID, AgentName: t.agentName, Revision: -1}
+	}
+	if goal, ok := argsMap["goal"].(string); ok && goal != "" {
+		plan.Goal = goal
+	}
+
+	if rawSteps, ok := argsMap["steps"].([]any); ok && len(rawSteps) > 0 {
+		steps, err := parsePlanSteps(rawSteps)
+		if err != nil {
+			return nil, err
+		}
+		plan.Steps = steps
+		plan.Revision++
+	} else if number, ok := planStepNumber(argsMap["step"]); ok {
+		if !exists {
+			return nil, fmt.Errorf("there is no plan yet; create one by passing 'steps'")
+		}
+		i := number - 1
+		if i < 0 || i >= len(plan.Steps) {
+			return nil, fmt.Errorf("step %d does not exist; the plan has %d steps", number, len(plan.Steps))
+		}
+		status, _ := argsMap["status"].(string)
+		if !slices.Contains(planStepStatuses, status) {
+			return nil, fmt.Errorf("invalid status '%s'; expected one of: %s", status, strings.Join(planStepStatuses, ", "))
+		}
+		plan.Steps[i].Status = PlanStepStatus(status)
+		if note, ok := argsMap["note"].(string); ok {
+			plan.Steps[i].Note = note
+		}
+	} else {
+		return nil, fmt.Errorf("pass either 'steps' or 'step' with a 'status'")
+	}
+
+	invCtx.SetState(t.planner.stateKey(), planToState(plan))
+	if sender, ok := invocation.GetUISender(ctx); ok {
+		sender(invocation.PlanUpdateEvent, plan)
+	}
+	invocation.SendInternalLog(ctx, "Agent '%s' updated its plan:\n%s", t.agentName, plan.Checklist())
+	return map[string]any{"plan": plan.Checklist(), "finished": plan.Finished()}, nil
+}
+
+func parsePlanSteps(rawSteps []any) ([]PlanStep, error) {
+	steps := make([]PlanStep, 0, len(rawSteps))
+	for i, raw := range rawSteps {
+		var step PlanStep
+		switch v := raw.(type) {
+		case string:
+			step.Title = v
+		case map[string]any:
+			step.Title, _ = v["title"].(string)
+			status, _ := v["status"].(string)
+			if status != "" && !slices.Contains(planStepStatuses, status) {
+				return nil, fmt.Errorf("step %d has invalid status '%s'; expected one of: %s", i+1, status, strings.Join(planStepStatuses, ", "))
+			}
+			step.Status = PlanStepStatus(status)
+			step.Note, _ = v["note"].(string)
+		}
+		if strings.TrimSpace(step.Title) == "" {
+			return nil, fmt.Errorf("step %d has no title", i+1)
+		}
+		if step.Status == "" {
+			step.Status = PlanStepPending
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// planStepNumber accepts the step number as decoded from JSON or given by Go
+// callers.
+func planStepNumber(value any) (int, bool) {
+	switch v := value.(type) {
+	case float64:
+		return int(v), true
+	case int:
+		return v, true
+	}
+	return 0, false
+}
+
+// appendInstruction returns a copy of instruction with text added to its
+// first text part, which is the part the model providers send.
+func appendInstruction(instruction *modelstypes.Message, text string) *modelstypes.Message {
+	if text == "" {
+		return instruction
+	}
+	if instruction == nil {
+		return &modelstypes.Message{Role: "system", Parts: []modelstypes.Part{{Text: &text}}}
+	}
+	msg := *instruction
+	msg.Parts = append([]modelstypes.Part{}, instruction.Parts...)
+	for i, part := range msg.Parts {
+		if part.Text != nil {
+			combined := *part.Text + "\n\n" + text
+			msg.Parts[i].Text = &combined
+			return &msg
+		}
+	}
+	msg.Parts = append([]modelstypes.Part{{Text: &text}}, msg.Parts...)
+	return &msg
+}
@@ -0,0 +1,196 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/KennethanCeyer/adk-go/agents/interfaces"
+	"github.com/KennethanCeyer/adk-go/agents/invocation"
+	modelstypes "github.com/KennethanCeyer/adk-go/models/types"
+)
+
+type Verdict string
+
+const (
+	VerdictApprove Verdict = "approve"
+	VerdictRevise  Verdict = "revise"
+)
+
+// Critique is the critic's review of one draft.
+type Critique struct {
+	Round    int     `json:"round"`
+	Draft    string  `json:"draft"`
+	Verdict  Verdict `json:"verdict"`
+	Feedback string  `json:"feedback"`
+}
+
+// ReflectionResult reports how a ReflectionAgent run ended.
+type ReflectionResult struct {
+	Response  *modelstypes.Message // The final draft
+	Approved  bool
+	Rounds    int
+	Critiques []Critique // One per round, in order
+}
+
+// ReflectionAgent drafts an answer with Generator and has Critic review it.
+// The critic answers with a JSON verdict, {"verdict": "approve" | "revise",
+// "feedback": "..."}, and as long as it asks for a revision the generator
+// gets its previous draft and the feedback back to write the next one. The
+// agent stops when a draft is approved or after MaxRounds drafts, and
+// responds with the last draft either way.
+type ReflectionAgent struct {
+	AgentName        string
+	AgentDescription string
+	Generator        interfaces.Agent
+	Critic           interfaces.Agent
+	MaxRounds        int
+	// ResultKey, when set, stores the outcome of each run in the session
+	// state as {"approved", "rounds", "critiques"}.
+	ResultKey string
+}
+
+func NewReflectionAgent(name, description string, generator, critic interfaces.Agent, maxRounds int) *ReflectionAgent {
+	return &ReflectionAgent{
+		AgentName:        name,
+		AgentDescription: description,
+		Generator:        generator,
+		Critic:           critic,
+		MaxRounds:        maxRounds,
+	}
+}
+
+func (a *ReflectionAgent) GetName() string        { return a.AgentName }
+func (a *ReflectionAgent) GetDescription() string { return a.AgentDescription }
+
+func (a *ReflectionAgent) GetSubAgents() []interfaces.Agent {
+	return []interfaces.Agent{a.Generator, a.Critic}
+}
+
+func (a *ReflectionAgent) Process(
+	ctx context.Context,
+	history []modelstypes.Message,
+	latestContent modelstypes.Message,
+) (*modelstypes.Message, error) {
+	result, err := a.Run(ctx, history, latestContent)
+	if err != nil {
+		return nil, err
+	}
+	return result.Response, nil
+}
+
+// Run executes the draft-critique cycle like Process and also returns the
+// critique of every round.
+func (a *ReflectionAgent) Run(
+	ctx context.Context,
+	history []modelstypes.Message,
+	latestContent modelstypes.Message,
+) (*ReflectionResult, error) {
+	if a.Generator == nil || a.Critic == nil {
+		return nil, fmt.Errorf("reflection agent '%s' needs both a generator and a critic", a.AgentName)
+	}
+	if a.MaxRounds <= 0 {
+		return nil, fmt.Errorf("reflection agent '%s' needs a positive MaxRounds, got %d", a.AgentName, a.MaxRounds)
+	}
+	invocation.SendInternalLog(ctx, "Starting reflection for agent '%s' (max %d rounds)...", a.AgentName, a.MaxRounds)
+
+	request := messageText(latestContent)
+	result := &ReflectionResult{}
+	input := latestContent
+	for round := 1; round <= a.MaxRounds; round++ {
+		result.Rounds = round
+		invocation.SendInternalLog(ctx, "Reflection round %d/%d: drafting with '%s'", round, a.MaxRounds, a.Generator.GetName())
+		draft, err := a.Generator.Process(ctx, history, input)
+		if err != nil {
+			return nil, fmt.Errorf("generator '%s' failed in round %d: %w", a.Generator.GetName(), round, err)
+		}
+		if draft == nil {
+			return nil, fmt.Errorf("generator '%s' returned no draft in round %d", a.Generator.GetName(), round)
+		}
+		result.Response = draft
+		draftText := messageText(*draft)
+
+		critique, err := a.critique(ctx, history, request, draftText, round)
+		if err != nil {
+			return nil, err
+		}
+		result.Critiques = append(result.Critiques, *critique)
+		if critique.Verdict == VerdictApprove {
+			result.Approved = true
+			invocation.SendInternalLog(ctx, "Critic '%s' approved the draft of round %d.", a.Critic.GetName(), round)
+			break
+		}
+		invocation.SendInternalLog(ctx, "Critic '%s' asked for a revision: %s", a.Critic.GetName(), critique.Feedback)
+		input = revisionRequest(request, draftText, critique.Feedback)
+	}
+
+	if !result.Approved {
+		invocation.SendInternalLog(ctx, "Reflection ended after %d rounds without approval.", result.Rounds)
+	}
+	if a.ResultKey != "" {
+		critiques := make([]any, len(result.Critiques))
+		for i, c := range result.Critiques {
+			critiques[i] = map[string]any{
+				"round":    c.Round,
+				"draft":    c.Draft,
+				"verdict":  string(c.Verdict),
+				"feedback": c.Feedback,
+			}
+		}
+		invocation.FromContext(ctx).SetState(a.ResultKey, map[string]any{
+			"approved":  result.Approved,
+			"rounds":    result.Rounds,
+			"critiques": critiques,
+		})
+	}
+	return result, nil
+}
+
+// critique asks the critic to review draft and parses its verdict.
+func (a *ReflectionAgent) critique(ctx context.Context, history []modelstypes.Message, request, draft string, round int) (*Critique, error) {
+	promptText := fmt.Sprintf("Review the following draft written in response to the request below.\n\n"+
+		"Request:\n%s\n\nDraft:\n%s\n\n"+
+		"Answer with a JSON object only, in the form {\"verdict\": \"approve\" or \"revise\", \"feedback\": \"...\"}. "+
+		"Approve the draft if it needs no further changes; otherwise explain in the feedback what to change.",
+		request, draft)
+	prompt := modelstypes.Message{Role: "user", Parts: []modelstypes.Part{{Text: &promptText}}}
+
+	response, err := a.Critic.Process(ctx, history, prompt)
+	if err != nil {
+		return nil, fmt.Errorf("critic '%s' failed in round %d: %w", a.Critic.GetName(), round, err)
+	}
+	if response == nil {
+		return nil, fmt.Errorf("critic '%s' returned no verdict in round %d", a.Critic.GetName(), round)
+	}
+	verdict, feedback, err := parseVerdict(messageText(*response))
+	if err != nil {
+		return nil, fmt.Errorf("critic '%s' in round %d: %w", a.Critic.GetName(), round, err)
+	}
+	return &Critique{Round: round, Draft: draft, Verdict: verdict, Feedback: feedback}, nil
+}
+
+// parseVerdict reads the critic's JSON answer, which may be wrapped in a
+// ```json fence.
+func parseVerdict(text string) (Verdict, string, error) {
+	value, ok := parseStructuredOutput(text)
+	fields, isObject := value.(map[string]any)
+	if !ok || !isObject {
+		return "", "", fmt.Errorf("expected a JSON object with 'verdict' and 'feedback', got: %s", text)
+	}
+	feedback, _ := fields["feedback"].(string)
+	raw, _ := fields["verdict"].(string)
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "approve", "approved":
+		return VerdictApprove, feedback, nil
+	case "revise":
+		return VerdictRevise, feedback, nil
+	}
+	return "", "", fmt.Errorf("unknown verdict '%s'; expected 'approve' or 'revise'", raw)
+}
+
+func revisionRequest(request, draft, feedback string) modelstypes.Message {
+	text := fmt.Sprintf("Original request:\n%s\n\nYour previous draft:\n%s\n\nReviewer feedback:\n%s\n\n"+
+		"Revise the draft to address the feedback. Reply with the complete revised draft only.",
+		request, draft, feedback)
+	return modelstypes.Message{Role: "user", Parts: []modelstypes.Part{{Text: &text}}}
+}
@@ -0,0 +1,110 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	modelstypes "github.com/KennethanCeyer/adk-go/models/types"
+)
+
+// scriptedAgent answers with the given replies in order and records the
+// messages it received.
+type scriptedAgent struct {
+	*FuncAgent
+	inputs []string
+}
+
+func newScriptedAgent(name string, replies ...string) *scriptedAgent {
+	s := &scriptedAgent{}
+	s.FuncAgent = NewFuncAgent(name, "", func(_ context.Context, _ []modelstypes.Message, input modelstypes.Message) (*modelstypes.Message, error) {
+		s.inputs = append(s.inputs, messageText(input))
+		if len(s.inputs) > len(replies) {
+			return nil, fmt.Errorf("%s was called %d times, expected at most %d", name, len(s.inputs), len(replies))
+		}
+		text := replies[len(s.inputs)-1]
+		return &modelstypes.Message{Role: "model", Parts: []modelstypes.Part{{Text: &text}}}, nil
+	})
+	return s
+}
+
+func TestReflectionAgentRun(t *testing.T) {
+	const revise = `{"verdict": "revise", "feedback": "shorter"}`
+	const approve = "```json\n{\"verdict\": \"approve\", \"feedback\": \"\"}\n```"
+	tests := []struct {
+		name      string
+		maxRounds int
+		drafts    []string
+		verdicts  []string
+		response  string
+		approved  bool
+		rounds    int
+	}{
+		{"approved first draft", 3, []string{"draft 1"}, []string{approve}, "draft 1", true, 1},
+		{"approved after revision", 3, []string{"draft 1", "draft 2"}, []string{revise, approve}, "draft 2", true, 2},
+		{"rounds exhausted", 2, []string{"draft 1", "draft 2"}, []string{revise, revise}, "draft 2", false, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator := newScriptedAgent("generator", tt.drafts...)
+			critic := newScriptedAgent("critic", tt.verdicts...)
+			agent := NewReflectionAgent("reflect", "", generator, critic, tt.maxRounds)
+			text := "write a poem"
+			result, err := agent.Run(context.Background(), nil, modelstypes.Message{Role: "user", Parts: []modelstypes.Part{{Text: &text}}})
+			if err != nil {
+				t.Fatalf("Run: %v", err)
+			}
+			if got := messageText(*result.Response); got != tt.response {
+				t.Errorf("response = %q, want %q", got, tt.response)
+			}
+			if result.Approved != tt.approved || result.Rounds != tt.rounds || len(result.Critiques) != tt.rounds {
+				t.Errorf("approved = %v, rounds = %d, critiques = %d; want %v, %d, %d", result.Approved, result.Rounds, len(result.Critiques), tt.approved, tt.rounds, tt.rounds)
+			}
+			for round, input := range generator.inputs[1:] {
+				if !strings.Contains(input, "draft "+fmt.Sprint(round+1)) || !strings.Contains(input, "shorter") {
+					t.Errorf("revision request %d = %q, want the previous draft and the feedback", round+1, input)
+				}
+			}
+		})
+	}
+}
+
+func TestReflectionAgentRequiresPositiveMaxRounds(t *testing.T) {
+	agent := NewReflectionAgent("reflect", "", newScriptedAgent("generator"), newScriptedAgent("critic"), 0)
+	response, err := agent.Process(context.Background(), nil, modelstypes.Message{Role: "user"})
+	if err == nil || !strings.Contains(err.Error(), "positive MaxRounds") {
+		t.Errorf("Process = %v, %v; want a MaxRounds error", response, err)
+	}
+}
+
+func TestParseVerdict(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		verdict  Verdict
+		feedback string
+		wantErr  bool
+	}{
+		{"approve", `{"verdict": "approve"}`, VerdictApprove, "", false},
+		{"approved", `{"verdict": " Approved "}`, VerdictApprove, "", false},
+		{"revise with feedback", `{"verdict": "revise", "feedback": "add examples"}`, VerdictRevise, "add examples", false},
+		{"fenced", "```json\n{\"verdict\": \"revise\", \"feedback\": \"x\"}\n```", VerdictRevise, "x", false},
+		{"prose", "Looks good to me!", "", "", true},
+		{"array", `["approve"]`, "", "", true},
+		{"unknown verdict", `{"verdict": "maybe"}`, "", "", true},
+		{"missing verdict", `{"feedback": "fine"}`, "", "", true},
+		{"verdict not a string", `{"verdict": true}`, "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verdict, feedback, err := parseVerdict(tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseVerdict(%q) error = %v, want error %v", tt.text, err, tt.wantErr)
+			}
+			if verdict != tt.verdict || feedback != tt.feedback {
+				t.Errorf("parseVerdict(%q) = %q, %q; want %q, %q", tt.text, verdict, feedback, tt.verdict, tt.feedback)
+			}
+		})
+	}
+}
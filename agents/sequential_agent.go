@@ -6,18 +6,16 @@ import (
 
 	"github.com/KennethanCeyer/adk-go/agents/interfaces"
 	"github.com/KennethanCeyer/adk-go/agents/invocation"
-	"github.com/KennethanCeyer/adk-go/llmproviders"
 	modelstypes "github.com/KennethanCeyer/adk-go/models/types"
-	"github.com/KennethanCeyer/adk-go/tools"
 )
 
 type SequentialAgent struct {
 	AgentName        string
 	AgentDescription string
-	SubAgents        []interfaces.LlmAgent
+	SubAgents        []interfaces.Agent
 }
 
-func NewSequentialAgent(name, description string, subAgents []interfaces.LlmAgent) *SequentialAgent {
+func NewSequentialAgent(name, description string, subAgents []interfaces.Agent) *SequentialAgent {
 	return &SequentialAgent{
 		AgentName:        name,
 		AgentDescription: description,
@@ -25,12 +23,9 @@ func NewSequentialAgent(name, description string, subAgents []interfaces.LlmAgen
 	}
 }
 
-func (a *SequentialAgent) GetName() string                            { return a.AgentName }
-func (a *SequentialAgent) GetDescription() string                     { return a.AgentDescription }
-func (a *SequentialAgent) GetModelIdentifier() string                 { return "workflow-sequential" }
-func (a *SequentialAgent) GetSystemInstruction() *modelstypes.Message { return nil }
-func (a *SequentialAgent) GetTools() []tools.Tool                     { return nil }
-func (a *SequentialAgent) GetLLMProvider() llmproviders.LLMProvider   { return nil }
+func (a *SequentialAgent) GetName() string                  { return a.AgentName }
+func (a *SequentialAgent) GetDescription() string           { return a.AgentDescription }
+func (a *SequentialAgent) GetSubAgents() []interfaces.Agent { return a.SubAgents }
 
 func (a *SequentialAgent) Process(
 	ctx context.Context,
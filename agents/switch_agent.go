@@ -0,0 +1,197 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/KennethanCeyer/adk-go/agents/interfaces"
+	"github.com/KennethanCeyer/adk-go/agents/invocation"
+	"github.com/KennethanCeyer/adk-go/llmproviders"
+	modelstypes "github.com/KennethanCeyer/adk-go/models/types"
+)
+
+// Condition reports whether a branch should handle the message. state is a
+// copy of the session state, empty outside a session, and latest is the
+// message the switch received, which inside a SequentialAgent is the previous
+// agent's response.
+type Condition func(state map[string]any, latest modelstypes.Message) bool
+
+// StateEquals returns a Condition that holds when the session state has value
+// under key. Values are compared with reflect.DeepEqual, so maps and slices
+// decoded from JSON state can be matched too.
+func StateEquals(key string, value any) Condition {
+	return func(state map[string]any, _ modelstypes.Message) bool {
+		v, ok := state[key]
+		return ok && reflect.DeepEqual(v, value)
+	}
+}
+
+// ResponseContains returns a Condition that holds when the text of the latest
+// message contains substr, ignoring case.
+func ResponseContains(substr string) Condition {
+	return func(_ map[string]any, latest modelstypes.Message) bool {
+		return strings.Contains(strings.ToLower(messageText(latest)), strings.ToLower(substr))
+	}
+}
+
+type Branch struct {
+	// Name labels the branch in logs and graphs and is the category the
+	// classifier answers with.
+	Name string
+	// Description tells the classifier when to choose the branch.
+	Description string
+	// When selects the branch without asking the classifier. Branches
+	// without a condition can only be chosen by the classifier.
+	When  Condition
+	Agent interfaces.Agent
+}
+
+// SwitchAgent routes each message to exactly one of its branches. Conditions
+// are evaluated in order and the first match wins. If none matches and a
+// Provider is set, an LLM classifier picks one of the branches without a
+// condition. Otherwise, or if the classifier's answer matches no branch, the
+// Default agent runs.
+type SwitchAgent struct {
+	AgentName        string
+	AgentDescription string
+	Branches         []Branch
+	Default          interfaces.Agent
+	Provider         llmproviders.LLMProvider // Optional, used by the classifier
+	ModelID          string
+}
+
+func NewSwitchAgent(name, description string, branches []Branch, defaultAgent interfaces.Agent) *SwitchAgent {
+	return &SwitchAgent{
+		AgentName:        name,
+		AgentDescription: description,
+		Branches:         branches,
+		Default:          defaultAgent,
+	}
+}
+
+func (a *SwitchAgent) GetName() string        { return a.AgentName }
+func (a *SwitchAgent) GetDescription() string { return a.AgentDescription }
+
+func (a *SwitchAgent) GetSubAgents() []interfaces.Agent {
+	var subAgents []interfaces.Agent
+	for _, branch := range a.Branches {
+		subAgents = append(subAgents, branch.Agent)
+	}
+	if a.Default != nil {
+		subAgents = append(subAgents, a.Default)
+	}
+	return subAgents
+}
+
+func (a *SwitchAgent) Process(
+	ctx context.Context,
+	history []modelstypes.Message,
+	latestContent modelstypes.Message,
+) (*modelstypes.Message, error) {
+	agent, label, err := a.route(ctx, latestContent)
+	if err != nil {
+		return nil, err
+	}
+	if agent == nil {
+		return nil, fmt.Errorf("switch '%s': no branch matched and no default agent is configured", a.AgentName)
+	}
+	invocation.SendInternalLog(ctx, "Switch '%s' selected %s: %s", a.AgentName, label, agent.GetName())
+
+	response, err := agent.Process(ctx, history, latestContent)
+	if err != nil {
+		return nil, fmt.Errorf("sub-agent '%s' failed: %w", agent.GetName(), err)
+	}
+	return response, nil
+}
+
+// route picks the agent for msg and describes how it was chosen.
+func (a *SwitchAgent) route(ctx context.Context, msg modelstypes.Message) (interfaces.Agent, string, error) {
+	state := invocation.FromContext(ctx).StateSnapshot()
+	for i, branch := range a.Branches {
+		if branch.When != nil && branch.When(state, msg) {
+			return branch.Agent, fmt.Sprintf("branch '%s' by condition", a.BranchLabel(i)), nil
+		}
+	}
+
+	if a.Provider != nil {
+		i, err := a.classify(ctx, msg)
+		if err != nil {
+			return nil, "", err
+		}
+		if i >= 0 {
+			return a.Branches[i].Agent, fmt.Sprintf("branch '%s' by classifier", a.BranchLabel(i)), nil
+		}
+	}
+	return a.Default, "the default branch", nil
+}
+
+// classify asks the model which of the branches without a condition fits msg
+// and returns its index, or -1 if the answer names none of them.
+func (a *SwitchAgent) classify(ctx context.Context, msg modelstypes.Message) (int, error) {
+	var candidates []int
+	var options strings.Builder
+	for i, branch := range a.Branches {
+		if branch.When != nil || branch.Name == "" {
+			continue
+		}
+		candidates = append(candidates, i)
+		fmt.Fprintf(&options, "- %s", branch.Name)
+		if branch.Description != "" {
+			fmt.Fprintf(&options, ": %s", branch.Description)
+		}
+		options.WriteString("\n")
+	}
+	if len(candidates) == 0 {
+		return -1, nil
+	}
+
+	invocation.SendInternalLog(ctx, "Switch '%s' is classifying the message into %d categories...", a.AgentName, len(candidates))
+	promptText := fmt.Sprintf("Classify the following message into exactly one of these categories:\n\n%s\nIf none of them fits, answer 'none'. Answer with the category name only.\n\nMessage:\n%s", options.String(), messageText(msg))
+	prompt := modelstypes.Message{Role: "user", Parts: []modelstypes.Part{{Text: &promptText}}}
+	response, err := generate(ctx, a.AgentName, a.Provider, a.ModelID, nil, prompt)
+	if err != nil {
+		return -1, fmt.Errorf("switch '%s' classification failed: %w", a.AgentName, err)
+	}
+	if response == nil {
+		return -1, nil
+	}
+
+	answer := strings.ToLower(strings.Trim(messageText(*response), " \t\r\n.'\"`*"))
+	for _, i := range candidates {
+		if answer == strings.ToLower(a.Branches[i].Name) {
+			return i, nil
+		}
+	}
+	// Tolerate chatty answers as long as they mention a single category.
+	match := -1
+	for _, i := range candidates {
+		if strings.Contains(answer, strings.ToLower(a.Branches[i].Name)) {
+			if match >= 0 {
+				return -1, nil
+			}
+			match = i
+		}
+	}
+	return match, nil
+}
+
+// BranchLabel returns the name of the i-th branch, or a positional label for
+// unnamed branches.
+func (a *SwitchAgent) BranchLabel(i int) string {
+	if name := a.Branches[i].Name; name != "" {
+		return name
+	}
+	return fmt.Sprintf("branch %d", i+1)
+}
+
+func messageText(msg modelstypes.Message) string {
+	var texts []string
+	for _, part := range msg.Parts {
+		if part.Text != nil {
+			texts = append(texts, *part.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
@@ -0,0 +1,33 @@
+package agents
+
+import (
+	"testing"
+
+	modelstypes "github.com/KennethanCeyer/adk-go/models/types"
+)
+
+func TestStateEquals(t *testing.T) {
+	state := map[string]any{
+		"tier":   "gold",
+		"tags":   []any{"a", "b"},
+		"filter": map[string]any{"region": "eu"},
+	}
+	tests := []struct {
+		key   string
+		value any
+		want  bool
+	}{
+		{"tier", "gold", true},
+		{"tier", "silver", false},
+		{"tags", []any{"a", "b"}, true},
+		{"tags", []any{"b"}, false},
+		{"filter", map[string]any{"region": "eu"}, true},
+		{"filter", map[string]any{"region": "us"}, false},
+		{"missing", nil, false},
+	}
+	for _, tt := range tests {
+		if got := StateEquals(tt.key, tt.value)(state, modelstypes.Message{}); got != tt.want {
+			t.Errorf("StateEquals(%q, %v) = %v, want %v", tt.key, tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package agents
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/KennethanCeyer/adk-go/agents/invocation"
+	"github.com/google/uuid"
+)
+
+// TruncationStrategy decides which part of an oversized tool result the model
+// gets to see.
+type TruncationStrategy string
+
+const (
+	// TruncateHead keeps the beginning of the result.
+	TruncateHead TruncationStrategy = "head"
+	// TruncateTail keeps the end of the result, e.g. the last lines of a log.
+	TruncateTail TruncationStrategy = "tail"
+	// TruncateHeadTail keeps the beginning and the end and drops the middle.
+	TruncateHeadTail TruncationStrategy = "head_tail"
+)
+
+// ToolResultPolicy limits the size of the tool results handed to the model.
+type ToolResultPolicy struct {
+	// MaxBytes is the largest serialized (JSON) result passed through as is.
+	// Zero disables the limit.
+	MaxBytes int
+	// Strategy selects the preview kept for oversized results. Defaults to
+	// TruncateHeadTail.
+	Strategy TruncationStrategy
+	// OffloadToArtifact stores oversized results in full as a session
+	// artifact and gives the agent a read_artifact tool to page through them.
+	OffloadToArtifact bool
+}
+
+// apply returns result unchanged if it fits, or a summary with a truncated
+// preview and, when offloading is enabled, a reference to the artifact
+// holding the full result. The preview is sized so that the serialized
+// summary, notice included, fits in MaxBytes.
+func (p *ToolResultPolicy) apply(ctx context.Context, toolName string, result map[string]any) map[string]any {
+	if p == nil || p.MaxBytes <= 0 {
+		return result
+	}
+	data, err := json.Marshal(result)
+	if err != nil || len(data) <= p.MaxBytes {
+		return result
+	}
+
+	summary := map[string]any{
+		"truncated":      true,
+		"original_bytes": len(data),
+	}
+	sess := invocation.FromContext(ctx).Session
+	if p.OffloadToArtifact && sess != nil {
+		name := fmt.Sprintf("%s-%s.json", toolName, uuid.NewString()[:8])
+		sess.SaveArtifact(name, "application/json", data)
+		summary["artifact"] = name
+		summary["note"] = fmt.Sprintf("The result was too large and has been stored as artifact '%s'. The preview only shows part of it; use the read_artifact tool to read the rest.", name)
+		invocation.SendInternalLog(ctx, "  - Tool '%s' result (%d bytes) was offloaded to artifact '%s'", toolName, len(data), name)
+	} else {
+		summary["note"] = fmt.Sprintf("The result was too large and has been truncated to %d bytes.", p.MaxBytes)
+		invocation.SendInternalLog(ctx, "  - Tool '%s' result (%d bytes) was truncated", toolName, len(data))
+	}
+	summary["preview"] = p.preview(summary, string(data))
+	return summary
+}
+
+// preview truncates data so that summary fits in MaxBytes once data is added
+// to it as its preview. The JSON escaping of the preview is not known until
+// it is cut, so the cut is shrunk by the overshoot until it fits.
+func (p *ToolResultPolicy) preview(summary map[string]any, data string) string {
+	summary["preview"] = ""
+	empty, _ := json.Marshal(summary)
+	budget := p.MaxBytes - len(empty)
+	for budget > 0 {
+		preview := truncate(data, budget, p.Strategy)
+		summary["preview"] = preview
+		out, _ := json.Marshal(summary)
+		if len(out) <= p.MaxBytes {
+			return preview
+		}
+		budget -= len(out) - p.MaxBytes
+	}
+	return ""
+}
+
+// truncate cuts s to at most maxBytes, the truncation marker included.
+func truncate(s string, maxBytes int, strategy TruncationStrategy) string {
+	const marker = "\n... [truncated] ...\n"
+	keep := maxBytes - len(marker)
+	if keep <= 0 {
+		return ""
+	}
+	switch strategy {
+	case TruncateHead:
+		return validUTF8(s[:keep]) + marker
+	case TruncateTail:
+		return marker + validUTF8(s[len(s)-keep:])
+	default:
+		head := keep / 2
+		return validUTF8(s[:head]) + marker + validUTF8(s[len(s)-(keep-head):])
+	}
+}
+
+// validUTF8 drops the partial runes left at the edges of a byte slice cut.
+func validUTF8(s string) string {
+	return strings.ToValidUTF8(s, "")
+}
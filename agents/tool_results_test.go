@@ -0,0 +1,61 @@
+package agents
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/KennethanCeyer/adk-go/agents/invocation"
+	"github.com/KennethanCeyer/adk-go/sessions"
+)
+
+func TestToolResultSummaryFitsMaxBytes(t *testing.T) {
+	// Quotes and newlines grow when the preview is escaped as a JSON string.
+	result := map[string]any{"lines": strings.Repeat("\"quoted\"\nline é\n", 500)}
+	sessCtx := invocation.WithInvocationContext(context.Background(), &invocation.InvocationContext{Session: &sessions.Session{ID: "s"}})
+
+	for _, strategy := range []TruncationStrategy{TruncateHead, TruncateTail, TruncateHeadTail} {
+		for _, offload := range []bool{false, true} {
+			for _, maxBytes := range []int{400, 1000, 4000} {
+				policy := &ToolResultPolicy{MaxBytes: maxBytes, Strategy: strategy, OffloadToArtifact: offload}
+				summary := policy.apply(sessCtx, "big_tool", result)
+				data, err := json.Marshal(summary)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(data) > maxBytes {
+					t.Errorf("%s offload=%v: summary is %d bytes, want at most %d", strategy, offload, len(data), maxBytes)
+				}
+				if preview, _ := summary["preview"].(string); preview == "" {
+					t.Errorf("%s offload=%v max=%d: preview is empty", strategy, offload, maxBytes)
+				}
+			}
+		}
+	}
+}
+
+func TestReadArtifactToolNeedsSession(t *testing.T) {
+	agent := NewBaseLlmAgent("a", "", "model", nil, nil, nil).(*BaseLlmAgent)
+	agent.ToolResultPolicy = &ToolResultPolicy{MaxBytes: 100, OffloadToArtifact: true}
+
+	hasReadArtifact := func(ctx context.Context) bool {
+		resolved, err := agent.ResolveTools(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, tool := range resolved {
+			if tool.Name() == "read_artifact" {
+				return true
+			}
+		}
+		return false
+	}
+	if hasReadArtifact(context.Background()) {
+		t.Error("read_artifact is offered without a session")
+	}
+	sessCtx := invocation.WithInvocationContext(context.Background(), &invocation.InvocationContext{Session: &sessions.Session{ID: "s"}})
+	if !hasReadArtifact(sessCtx) {
+		t.Error("read_artifact is not offered with a session")
+	}
+}
@@ -0,0 +1,174 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/KennethanCeyer/adk-go/agents/interfaces"
+	"github.com/KennethanCeyer/adk-go/agents/invocation"
+	modelstypes "github.com/KennethanCeyer/adk-go/models/types"
+)
+
+const (
+	transferToolName = "transfer_to_agent"
+	// maxTransfersPerTurn stops agents from handing a turn back and forth forever.
+	maxTransfersPerTurn = 5
+)
+
+// FindAgent searches the agent tree below root, including root itself, for
+// the agent with the given name.
+func FindAgent(root interfaces.Agent, name string) interfaces.Agent {
+	if root == nil {
+		return nil
+	}
+	if root.GetName() == name {
+		return root
+	}
+	if parent, ok := root.(interfaces.ParentAgent); ok {
+		for _, sub := range parent.GetSubAgents() {
+			if found := FindAgent(sub, name); found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}
+
+// AddSubAgents makes the given agents children of a, so that a can transfer
+// control to them. LLM sub-agents get a as their parent and can transfer
+// back to it. An LLM agent can only have one parent: adding one that already
+// belongs to another agent, or adding an agent to itself, is an error and
+// leaves a unchanged.
+func (a *BaseLlmAgent) AddSubAgents(subAgents ...interfaces.Agent) error {
+	for _, sub := range subAgents {
+		llmSub, ok := sub.(*BaseLlmAgent)
+		switch {
+		case !ok:
+		case llmSub == a:
+			return fmt.Errorf("agent '%s' cannot be its own sub-agent", a.name)
+		case llmSub.parent != nil:
+			return fmt.Errorf("agent '%s' cannot be added to '%s': it is already a sub-agent of '%s'", llmSub.name, a.name, llmSub.parent.name)
+		}
+	}
+	for _, sub := range subAgents {
+		if sub == nil {
+			continue
+		}
+		if llmSub, ok := sub.(*BaseLlmAgent); ok {
+			llmSub.parent = a
+		}
+		a.subAgents = append(a.subAgents, sub)
+	}
+	return nil
+}
+
+func (a *BaseLlmAgent) GetSubAgents() []interfaces.Agent { return a.subAgents }
+
+// GetParent returns the agent a was added to with AddSubAgents, or nil.
+func (a *BaseLlmAgent) GetParent() *BaseLlmAgent { return a.parent }
+
+// transferTargets lists the agents a may hand control to: its sub-agents,
+// its parent and its siblings, unless the latter two are disallowed.
+func (a *BaseLlmAgent) transferTargets() []interfaces.Agent {
+	targets := append([]interfaces.Agent{}, a.subAgents...)
+	if a.parent != nil {
+		if !a.DisallowTransferToParent {
+			targets = append(targets, a.parent)
+		}
+		if !a.DisallowTransferToPeers {
+			for _, peer := range a.parent.subAgents {
+				if peer != interfaces.Agent(a) {
+					targets = append(targets, peer)
+				}
+			}
+		}
+	}
+	return targets
+}
+
+// transferToAgentTool is added automatically to agents that have transfer
+// targets. It only records the request; the agent performs the transfer once
+// the current tool calls are done.
+type transferToAgentTool struct {
+	targets []interfaces.Agent
+}
+
+func (t *transferToAgentTool) Name() string { return transferToolName }
+
+func (t *transferToAgentTool) Description() string {
+	var sb strings.Builder
+	sb.WriteString("Transfers the conversation to another agent that is better suited to handle the user's request. The chosen agent answers the current message and all following ones. Available agents:")
+	for _, target := range t.targets {
+		fmt.Fprintf(&sb, "\n- %s: %s", target.GetName(), target.GetDescription())
+	}
+	return sb.String()
+}
+
+func (t *transferToAgentTool) Parameters() any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"agent_name": map[string]any{
+				"type":        "string",
+				"description": "Name of the agent to transfer to.",
+				"enum":        t.names(),
+			},
+		},
+		"required": []string{"agent_name"},
+	}
+}
+
+func (t *transferToAgentTool) names() []string {
+	names := make([]string, len(t.targets))
+	for i, target := range t.targets {
+		names[i] = target.GetName()
+	}
+	return names
+}
+
+func (t *transferToAgentTool) Execute(ctx context.Context, args any) (any, error) {
+	argsMap, ok := args.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s: invalid arguments format, expected map[string]any, got %T", transferToolName, args)
+	}
+	name, ok := argsMap["agent_name"].(string)
+	if !ok || name == "" {
+		return nil, fmt.Errorf("%s: 'agent_name' is a required argument and must be a string", transferToolName)
+	}
+	if !slices.Contains(t.names(), name) {
+		return nil, fmt.Errorf("%s: cannot transfer to '%s'; choose one of: %s", transferToolName, name, strings.Join(t.names(), ", "))
+	}
+	invocation.FromContext(ctx).RequestTransfer(name)
+	return map[string]any{"status": "success", "message": fmt.Sprintf("Transferring to agent '%s'.", name)}, nil
+}
+
+// transfer hands the current user message to the named agent and returns its
+// response as the response of the turn. An LLM target that can transfer again
+// keeps the conversation for the following messages. Any other target, such
+// as a workflow or function agent, only answers this message: it has no way
+// to hand the conversation back, so a stays in charge of the following ones.
+func (a *BaseLlmAgent) transfer(ctx context.Context, name string, history []modelstypes.Message, latestMessage modelstypes.Message) (*modelstypes.Message, error) {
+	var target interfaces.Agent
+	for _, candidate := range a.transferTargets() {
+		if candidate.GetName() == name {
+			target = candidate
+			break
+		}
+	}
+	if target == nil {
+		return nil, fmt.Errorf("agent '%s' cannot transfer to unknown agent '%s'", a.name, name)
+	}
+	invCtx := invocation.FromContext(ctx)
+	if count := invCtx.RecordTransfer(name); count > maxTransfersPerTurn {
+		return nil, fmt.Errorf("exceeded maximum agent transfers (%d) in a single turn", maxTransfersPerTurn)
+	}
+	invocation.SendInternalLog(ctx, "Agent '%s' transferred the conversation to '%s'.", a.name, name)
+	if llmTarget, ok := target.(*BaseLlmAgent); ok && len(llmTarget.transferTargets()) > 0 {
+		return target.Process(ctx, history, latestMessage)
+	}
+	response, err := target.Process(ctx, history, latestMessage)
+	invCtx.ReturnTransfer(a.name)
+	return response, err
+}
@@ -0,0 +1,93 @@
+package agents
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/KennethanCeyer/adk-go/agents/invocation"
+	modelstypes "github.com/KennethanCeyer/adk-go/models/types"
+	"github.com/KennethanCeyer/adk-go/tools"
+)
+
+// transferringProvider asks for a transfer to target on its first call and
+// answers with text after that.
+type transferringProvider struct {
+	target string
+	calls  int
+}
+
+func (p *transferringProvider) GenerateContent(_ context.Context, _ string, _ *modelstypes.Message, _ []tools.Tool, _ []modelstypes.Message, _ modelstypes.Message) (*modelstypes.Message, error) {
+	p.calls++
+	if p.calls == 1 {
+		return &modelstypes.Message{Role: "model", Parts: []modelstypes.Part{{FunctionCall: &modelstypes.FunctionCall{
+			Name: transferToolName,
+			Args: map[string]any{"agent_name": p.target},
+		}}}}, nil
+	}
+	text := "done"
+	return &modelstypes.Message{Role: "model", Parts: []modelstypes.Part{{Text: &text}}}, nil
+}
+
+func newTestLlmAgent(name string, provider *transferringProvider) *BaseLlmAgent {
+	return NewBaseLlmAgent(name, name+" agent", "model", nil, provider, nil).(*BaseLlmAgent)
+}
+
+func TestTransferKeepsConversationWithLlmTarget(t *testing.T) {
+	root := newTestLlmAgent("root", &transferringProvider{target: "child"})
+	child := newTestLlmAgent("child", &transferringProvider{target: "root", calls: 1}) // Answers straight away
+	if err := root.AddSubAgents(child); err != nil {
+		t.Fatal(err)
+	}
+
+	invCtx := &invocation.InvocationContext{}
+	ctx := invocation.WithInvocationContext(context.Background(), invCtx)
+	if _, err := root.Process(ctx, nil, modelstypes.Message{Role: "user"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := invCtx.TransferredTo(); got != "child" {
+		t.Errorf("TransferredTo() = %q, want child", got)
+	}
+}
+
+func TestTransferReturnsFromWorkflowTarget(t *testing.T) {
+	root := newTestLlmAgent("root", &transferringProvider{target: "format"})
+	step := NewFuncAgent("format", "formats text", TextFunc(func(_ context.Context, text string) (string, error) {
+		return "formatted", nil
+	}))
+	if err := root.AddSubAgents(step); err != nil {
+		t.Fatal(err)
+	}
+
+	invCtx := &invocation.InvocationContext{}
+	ctx := invocation.WithInvocationContext(context.Background(), invCtx)
+	response, err := root.Process(ctx, nil, modelstypes.Message{Role: "user"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := messageText(*response); got != "formatted" {
+		t.Errorf("response = %q, want the function agent's answer", got)
+	}
+	if got := invCtx.TransferredTo(); got != "root" {
+		t.Errorf("TransferredTo() = %q, want the conversation back with root", got)
+	}
+}
+
+func TestAddSubAgentsRejectsReparenting(t *testing.T) {
+	first := newTestLlmAgent("first", nil)
+	second := newTestLlmAgent("second", nil)
+	child := newTestLlmAgent("child", nil)
+	if err := first.AddSubAgents(child); err != nil {
+		t.Fatal(err)
+	}
+	err := second.AddSubAgents(child)
+	if err == nil || !strings.Contains(err.Error(), "already a sub-agent of 'first'") {
+		t.Errorf("err = %v, want a re-parenting error", err)
+	}
+	if child.GetParent() != first || len(second.GetSubAgents()) != 0 {
+		t.Error("the failed call changed the agents")
+	}
+	if err := first.AddSubAgents(first); err == nil {
+		t.Error("an agent was added to itself")
+	}
+}
@@ -0,0 +1,207 @@
+package agents
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/KennethanCeyer/adk-go/agents/interfaces"
+	"github.com/KennethanCeyer/adk-go/tools/artifacts"
+)
+
+// ValidationError lists every problem Validate found in an agent tree.
+type ValidationError struct {
+	Root     string
+	Problems []string
+	// Agents names the agent each problem is about, by index into Problems,
+	// so that callers such as the config loader can point at its definition.
+	// It is empty for problems that concern no named agent.
+	Agents []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid agent tree '%s':\n  - %s", e.Root, strings.Join(e.Problems, "\n  - "))
+}
+
+// Validate checks the agent tree below root, including root itself. Agent
+// names must be non-empty and unique across the tree, since transfers,
+// session state and the web graph refer to agents by name. An agent must
+// not contain itself, an LLM agent must not have two tools with the same
+// name or a tool that shadows a built-in one, and workflow agents must be
+// runnable as configured. All problems are reported together as a
+// *ValidationError.
+//
+// Tools from Toolsets are only known at run time and are not checked.
+func Validate(root interfaces.Agent) error {
+	if root == nil {
+		return &ValidationError{Problems: []string{"the root agent is nil"}, Agents: []string{""}}
+	}
+	v := &validator{seen: make(map[string]placement)}
+	v.visit(root, "")
+	if len(v.problems) == 0 {
+		return nil
+	}
+	return &ValidationError{Root: root.GetName(), Problems: v.problems, Agents: v.agents}
+}
+
+type placement struct {
+	agent interfaces.Agent
+	where string
+}
+
+type validator struct {
+	seen     map[string]placement
+	path     []interfaces.Agent
+	problems []string
+	agents   []string
+}
+
+func (v *validator) addf(agent, format string, args ...any) {
+	v.problems = append(v.problems, fmt.Sprintf(format, args...))
+	v.agents = append(v.agents, agent)
+}
+
+func (v *validator) visit(agent interfaces.Agent, parent string) {
+	name := agent.GetName()
+	for i, ancestor := range v.path {
+		if ancestor == agent {
+			var cycle []string
+			for _, a := range v.path[i:] {
+				cycle = append(cycle, a.GetName())
+			}
+			v.addf(name, "agent cycle %s -> %s", strings.Join(cycle, " -> "), name)
+			return
+		}
+	}
+
+	where := "at the root"
+	if parent != "" {
+		where = fmt.Sprintf("under '%s'", parent)
+	}
+	if name == "" {
+		v.addf(parent, "an agent %s has no name", where)
+	} else if prev, exists := v.seen[name]; exists {
+		places := fmt.Sprintf("%s and %s", prev.where, where)
+		if prev.where == where {
+			places = "twice " + where
+		}
+		if prev.agent == agent {
+			v.addf(name, "agent '%s' is used in more than one place: %s", name, places)
+		} else {
+			v.addf(name, "duplicate agent name '%s': used %s", name, places)
+		}
+		return
+	} else {
+		v.seen[name] = placement{agent: agent, where: where}
+	}
+
+	before := len(v.problems)
+	v.checkAgent(agent)
+
+	parentAgent, ok := agent.(interfaces.ParentAgent)
+	if !ok {
+		return
+	}
+	v.path = append(v.path, agent)
+	var visited []interfaces.Agent
+	for i, sub := range parentAgent.GetSubAgents() {
+		if sub == nil {
+			v.addf(name, "agent '%s' has a nil sub-agent at position %d", name, i+1)
+			continue
+		}
+		// A switch may list the same agent as a branch and as its default.
+		if containsAgent(visited, sub) {
+			continue
+		}
+		visited = append(visited, sub)
+		v.visit(sub, name)
+	}
+	v.path = v.path[:len(v.path)-1]
+
+	// The graph's own checks repeat the name problems reported above.
+	if graph, ok := agent.(*GraphAgent); ok && len(v.problems) == before {
+		if _, err := graph.TopologicalOrder(); err != nil {
+			v.addf(name, "%v", err)
+		}
+	}
+}
+
+func (v *validator) checkAgent(agent interfaces.Agent) {
+	name := agent.GetName()
+	switch a := agent.(type) {
+	case *BaseLlmAgent:
+		if a.llmProvider == nil {
+			v.addf(name, "llm agent '%s' has no LLM provider", name)
+		}
+		if a.modelIdentifier == "" {
+			v.addf(name, "llm agent '%s' has no model", name)
+		}
+		for _, tool := range a.duplicateTools {
+			v.addf(name, "llm agent '%s' has more than one tool named '%s'", name, tool)
+		}
+		if _, exists := a.tools[transferToolName]; exists && len(a.transferTargets()) > 0 {
+			v.addf(name, "tool '%s' of llm agent '%s' collides with the built-in tool for transfers to its sub-agents", transferToolName, name)
+		}
+		if _, exists := a.tools[updatePlanToolName]; exists && a.Planner != nil {
+			if _, ok := a.Planner.(*PlanActPlanner); ok {
+				v.addf(name, "tool '%s' of llm agent '%s' collides with the built-in tool of its planner", updatePlanToolName, name)
+			}
+		}
+		readArtifact := (&artifacts.ReadArtifactTool{}).Name()
+		if _, exists := a.tools[readArtifact]; exists && a.ToolResultPolicy != nil && a.ToolResultPolicy.OffloadToArtifact {
+			v.addf(name, "tool '%s' of llm agent '%s' collides with the built-in tool for offloaded tool results", readArtifact, name)
+		}
+	case *SequentialAgent:
+		if len(a.SubAgents) == 0 {
+			v.addf(name, "sequential agent '%s' has no sub-agents", name)
+		}
+	case *ParallelAgent:
+		if len(a.SubAgents) == 0 {
+			v.addf(name, "parallel agent '%s' has no sub-agents", name)
+		}
+		if a.ErrorPolicy == Quorum && (a.Quorum <= 0 || a.Quorum > len(a.SubAgents)) {
+			v.addf(name, "parallel agent '%s': quorum must be between 1 and %d, got %d", name, len(a.SubAgents), a.Quorum)
+		}
+	case *LoopAgent:
+		if len(a.SubAgents) == 0 {
+			v.addf(name, "loop agent '%s' has no sub-agents", name)
+		}
+		if a.MaxIterations <= 0 {
+			v.addf(name, "loop agent '%s' needs a positive MaxIterations, got %d", name, a.MaxIterations)
+		}
+	case *SwitchAgent:
+		if len(a.Branches) == 0 && a.Default == nil {
+			v.addf(name, "switch agent '%s' has neither branches nor a default agent", name)
+		}
+	case *ReflectionAgent:
+		if a.MaxRounds <= 0 {
+			v.addf(name, "reflection agent '%s' needs a positive MaxRounds, got %d", name, a.MaxRounds)
+		}
+	case *MapAgent:
+		if a.MaxConcurrency < 0 {
+			v.addf(name, "map agent '%s' has a negative MaxConcurrency", name)
+		}
+		if a.MaxRetries < 0 {
+			v.addf(name, "map agent '%s' has negative MaxRetries", name)
+		}
+		if a.Reducer != nil && a.ReducerAgent != nil {
+			v.addf(name, "map agent '%s' has both a Reducer and a ReducerAgent; set only one", name)
+		}
+	case *GraphAgent:
+		if len(a.Nodes) == 0 {
+			v.addf(name, "graph agent '%s' has no nodes", name)
+		}
+	case *FuncAgent:
+		if a.Func == nil {
+			v.addf(name, "func agent '%s' has no function", name)
+		}
+	}
+}
+
+func containsAgent(list []interfaces.Agent, agent interfaces.Agent) bool {
+	for _, a := range list {
+		if a == agent {
+			return true
+		}
+	}
+	return false
+}
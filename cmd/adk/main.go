@@ -2,17 +2,26 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"strings"
 	"syscall"
 
 	"github.com/KennethanCeyer/adk-go/adk"
+	"github.com/KennethanCeyer/adk-go/agents"
+	"github.com/KennethanCeyer/adk-go/agents/interfaces"
+	"github.com/KennethanCeyer/adk-go/agents/invocation"
+	"github.com/KennethanCeyer/adk-go/config"
 	"github.com/KennethanCeyer/adk-go/examples"
+	"github.com/KennethanCeyer/adk-go/mcp"
 	"github.com/KennethanCeyer/adk-go/sessions"
+	"github.com/KennethanCeyer/adk-go/tools"
+	"github.com/KennethanCeyer/adk-go/tools/command"
 	"github.com/KennethanCeyer/adk-go/web"
 
 	_ "github.com/KennethanCeyer/adk-go/examples/file_based_chat"
@@ -21,6 +30,7 @@ import (
 	_ "github.com/KennethanCeyer/adk-go/examples/looping_guesser"
 	_ "github.com/KennethanCeyer/adk-go/examples/parallel_trip_planner"
 	_ "github.com/KennethanCeyer/adk-go/examples/sequential_weather"
+	_ "github.com/KennethanCeyer/adk-go/examples/support_router"
 )
 
 func main() {
@@ -41,6 +51,10 @@ func main() {
 		runCmd(os.Args[2:])
 	case "web":
 		webCmd(os.Args[2:])
+	case "mcp-serve":
+		mcpServeCmd(os.Args[2:])
+	case "tools":
+		toolsCmd(os.Args[2:])
 	default:
 		fmt.Printf("Unknown command: %s\n", command)
 		printUsage()
@@ -53,6 +67,8 @@ func printUsage() {
 	fmt.Println("\nAvailable commands:")
 	fmt.Println("  run                Run an agent in the command line")
 	fmt.Println("  web                Start a web server with a UI for an agent")
+	fmt.Println("  mcp-serve          Serve the registered agents as MCP tools")
+	fmt.Println("  tools              List or call tools loaded from YAML definitions")
 	fmt.Println("\nRun 'adk <command> -h' for more information on a specific command.")
 	fmt.Println("\nAvailable agents for 'run' and 'web' commands:")
 	fmt.Printf("  %s\n", strings.Join(examples.ListAgents(), ", "))
@@ -62,11 +78,40 @@ func runCmd(args []string) {
 	runFlagSet := flag.NewFlagSet("run", flag.ContinueOnError)
 	agentName := newAgentFlag(runFlagSet)
 	sessionID := runFlagSet.String("session-id", "", "ID of a previous session to resume.")
+	toolDefs := newToolDefsFlag(runFlagSet)
+	extraTools := runFlagSet.String("tools", "", "Comma-separated names of registered tools to attach to the agent.")
+	configFile := runFlagSet.String("config", "", "YAML or JSON agent definition to run instead of a registered agent.")
+	runConfig := newRunConfigFlags(runFlagSet)
 
 	err := runFlagSet.Parse(args)
 	if err != nil {
 		log.Fatalf("Error parsing flags for run command: %v", err)
 	}
+	loadToolDefs(*toolDefs)
+
+	var agentToRun interfaces.Agent
+	if *configFile != "" {
+		log.Printf("Loading agent from '%s'...", *configFile)
+		agentToRun, err = config.LoadFile(*configFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		*agentName = agentToRun.GetName()
+	} else {
+		log.Printf("Loading '%s' agent...", *agentName)
+		var found bool
+		agentToRun, found = examples.GetAgent(*agentName)
+		if !found {
+			log.Printf("Error: Unknown agent name '%s'.", *agentName)
+			printUsage()
+			os.Exit(1)
+		}
+	}
+
+	if agentToRun == nil {
+		log.Fatalf("Agent '%s' is not initialized. Check the corresponding examples/ package and ensure GEMINI_API_KEY is set.", *agentName)
+	}
 
 	var currentSession *sessions.Session
 	if *sessionID != "" {
@@ -82,16 +127,8 @@ func runCmd(args []string) {
 		log.Println("To resume this session later, use: -session-id=" + currentSession.ID)
 	}
 
-	log.Printf("Loading '%s' agent...", *agentName)
-	agentToRun, found := examples.GetAgent(*agentName)
-	if !found {
-		log.Printf("Error: Unknown agent name '%s'.", *agentName)
-		printUsage()
-		os.Exit(1)
-	}
-
-	if agentToRun == nil {
-		log.Fatalf("Agent '%s' is not initialized. Check the corresponding examples/ package and ensure GEMINI_API_KEY is set.", *agentName)
+	if *extraTools != "" {
+		attachTools(agentToRun, *extraTools)
 	}
 
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
@@ -101,6 +138,7 @@ func runCmd(args []string) {
 	if err != nil {
 		log.Fatalf("Failed to create agent runner: %v", err)
 	}
+	runner.RunConfig = runConfig
 
 	log.Printf("Starting agent runner...")
 	runner.Start(ctx)
@@ -134,12 +172,191 @@ func newAgentFlag(fs *flag.FlagSet) *string {
 func webCmd(args []string) {
 	webFlagSet := flag.NewFlagSet("web", flag.ContinueOnError)
 	port := webFlagSet.String("port", "8080", "Port to run the web server on")
+	configDir := webFlagSet.String("config-dir", "", "Directory of YAML or JSON agent definitions to serve alongside the registered agents.")
+	toolDefs := newToolDefsFlag(webFlagSet)
+	runConfig := newRunConfigFlags(webFlagSet)
 
 	err := webFlagSet.Parse(args)
 	if err != nil {
 		log.Fatalf("Error parsing flags for web command: %v", err)
 	}
+	loadToolDefs(*toolDefs)
+	if *configDir != "" {
+		loadConfigDir(*configDir)
+	}
 
 	addr := ":" + *port
-	web.StartServer(addr)
+	web.StartServer(addr, runConfig)
+}
+
+func mcpServeCmd(args []string) {
+	mcpFlagSet := flag.NewFlagSet("mcp-serve", flag.ContinueOnError)
+	transport := mcpFlagSet.String("transport", "stdio", "Transport to serve on: 'stdio' or 'http'")
+	addr := mcpFlagSet.String("addr", "127.0.0.1:8081", "Address to listen on when using the http transport")
+	exposeTools := mcpFlagSet.Bool("expose-tools", false, "Also expose each agent's individual tools as '<agent>__<tool>'")
+	runConfig := newRunConfigFlags(mcpFlagSet)
+
+	err := mcpFlagSet.Parse(args)
+	if err != nil {
+		log.Fatalf("Error parsing flags for mcp-serve command: %v", err)
+	}
+
+	server := mcp.NewServer("adk-go", "0.1.0")
+	for _, name := range examples.ListAgents() {
+		agent, found := examples.GetAgent(name)
+		if !found || agent == nil {
+			continue
+		}
+		agentTool := mcp.NewAgentTool(agent)
+		agentTool.(*mcp.AgentTool).RunConfig = runConfig
+		if err := server.AddTool(agentTool); err != nil {
+			log.Printf("Warning: skipping agent '%s': %v", name, err)
+			continue
+		}
+		if llmAgent, ok := agent.(interfaces.LlmAgent); ok && *exposeTools {
+			for _, tool := range llmAgent.GetTools() {
+				if err := server.AddToolAs(name+"__"+tool.Name(), tool); err != nil {
+					log.Printf("Warning: skipping tool '%s' of agent '%s': %v", tool.Name(), name, err)
+				}
+			}
+		}
+	}
+	log.Printf("Serving %d MCP tools: %s", len(server.ToolNames()), strings.Join(server.ToolNames(), ", "))
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	switch *transport {
+	case "stdio":
+		// Stdout carries the protocol, so all diagnostics go to stderr via the log package.
+		if err := server.ServeStdio(ctx, os.Stdin, os.Stdout); err != nil && ctx.Err() == nil {
+			log.Fatalf("MCP stdio server error: %v", err)
+		}
+	case "http":
+		mux := http.NewServeMux()
+		mux.Handle("/mcp", server)
+		httpServer := &http.Server{Addr: *addr, Handler: mux}
+		go func() {
+			<-ctx.Done()
+			_ = httpServer.Close()
+		}()
+		log.Printf("Serving MCP over HTTP at http://%s/mcp", *addr)
+		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("MCP http server error: %v", err)
+		}
+	default:
+		log.Fatalf("Unknown transport '%s'. Use 'stdio' or 'http'.", *transport)
+	}
+}
+
+func toolsCmd(args []string) {
+	toolsFlagSet := flag.NewFlagSet("tools", flag.ContinueOnError)
+	toolDefs := newToolDefsFlag(toolsFlagSet)
+	call := toolsFlagSet.String("call", "", "Name of a tool to call instead of listing the tools.")
+	callArgs := toolsFlagSet.String("args", "{}", "Arguments for -call as a JSON object.")
+
+	if err := toolsFlagSet.Parse(args); err != nil {
+		log.Fatalf("Error parsing flags for tools command: %v", err)
+	}
+	loadToolDefs(*toolDefs)
+
+	if *call == "" {
+		names := tools.ListTools()
+		if len(names) == 0 {
+			fmt.Println("No tools are registered. Load definitions with -tool-defs.")
+			return
+		}
+		for _, name := range names {
+			tool, _ := tools.GetTool(name)
+			fmt.Printf("  - %s: %s\n", name, tool.Description())
+		}
+		return
+	}
+
+	tool, found := tools.GetTool(*call)
+	if !found {
+		log.Fatalf("Unknown tool '%s'. Available: [%s]", *call, strings.Join(tools.ListTools(), ", "))
+	}
+	var parsedArgs map[string]any
+	if err := json.Unmarshal([]byte(*callArgs), &parsedArgs); err != nil {
+		log.Fatalf("Invalid -args: %v", err)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+	result, err := tool.Execute(ctx, parsedArgs)
+	if err != nil {
+		log.Fatalf("Tool '%s' failed: %v", *call, err)
+	}
+	out, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		log.Fatalf("Failed to encode result: %v", err)
+	}
+	fmt.Println(string(out))
+}
+
+// newRunConfigFlags adds the flags that limit each turn to fs. The returned
+// config is filled in when fs is parsed.
+func newRunConfigFlags(fs *flag.FlagSet) *invocation.RunConfig {
+	config := &invocation.RunConfig{}
+	fs.IntVar(&config.MaxLLMCalls, "max-llm-calls", 0, "Maximum model calls per turn, across all nested agents (0 for no limit).")
+	fs.IntVar(&config.MaxToolCalls, "max-tool-calls", 0, "Maximum tool calls per turn, across all nested agents (0 for no limit).")
+	fs.IntVar(&config.MaxTokens, "max-tokens", 0, "Maximum tokens per turn, across all nested agents (0 for no limit).")
+	fs.DurationVar(&config.MaxDuration, "timeout", 0, "Maximum wall time per turn, e.g. 2m (0 for no limit).")
+	fs.IntVar(&config.MaxStepsPerAgent, "max-steps", invocation.DefaultMaxStepsPerAgent, "Maximum model calls one LLM agent may make to answer a message.")
+	return config
+}
+
+func newToolDefsFlag(fs *flag.FlagSet) *string {
+	return fs.String("tool-defs", "", "Comma-separated YAML files of command-line tool definitions to register.")
+}
+
+// loadToolDefs registers the tools defined in a comma-separated list of YAML files.
+func loadToolDefs(paths string) {
+	if paths == "" {
+		return
+	}
+	for _, path := range strings.Split(paths, ",") {
+		loaded, err := command.RegisterFile(strings.TrimSpace(path))
+		if err != nil {
+			log.Fatalf("Error loading tool definitions: %v", err)
+		}
+		log.Printf("Loaded %d tools from '%s'", len(loaded), path)
+	}
+}
+
+// loadConfigDir registers the agents defined in the files of dir. Files that
+// fail to load are registered with their error, like examples that fail to
+// initialize.
+func loadConfigDir(dir string) {
+	entries, err := config.LoadDir(dir)
+	if err != nil {
+		log.Fatalf("Error loading agent definitions: %v", err)
+	}
+	for _, entry := range entries {
+		if entry.Err == nil {
+			log.Printf("Loaded agent '%s' from '%s'", entry.Name, entry.Path)
+		}
+		examples.RegisterAgent(entry.Name, entry.Agent, entry.Err)
+	}
+}
+
+// attachTools adds the named registered tools to an LLM agent.
+func attachTools(agent interfaces.Agent, names string) {
+	llmAgent, ok := agent.(*agents.BaseLlmAgent)
+	if !ok {
+		log.Fatalf("Tools can only be attached to LLM agents, but '%s' is a %T", agent.GetName(), agent)
+	}
+	var attached []tools.Tool
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		tool, found := tools.GetTool(name)
+		if !found {
+			log.Fatalf("Unknown tool '%s'. Available: [%s]", name, strings.Join(tools.ListTools(), ", "))
+		}
+		attached = append(attached, tool)
+	}
+	llmAgent.Toolsets = append(llmAgent.Toolsets, tools.ToolsetFunc(func(ctx context.Context) ([]tools.Tool, error) {
+		return attached, nil
+	}))
 }
@@ -0,0 +1,76 @@
+package codeexecutors
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	modelstypes "github.com/KennethanCeyer/adk-go/models/types"
+)
+
+var fencedBlockRe = regexp.MustCompile("(?s)```([A-Za-z0-9_+-]*)[ \t]*\r?\n(.*?)```")
+
+// languageAliases maps common language tags to the language names used by
+// executors.
+var languageAliases = map[string]string{
+	"py":      "python",
+	"python3": "python",
+	"golang":  "go",
+	"js":      "javascript",
+	"sh":      "bash",
+}
+
+// ExecuteTag marks a fenced code block as code the model wants executed.
+// "tool_code" on its own is the tag Gemini uses for Python; other languages
+// are tagged "tool_code_<language>", e.g. "tool_code_go".
+const ExecuteTag = "tool_code"
+
+// NormalizeLanguage lowercases a language name and resolves common aliases.
+func NormalizeLanguage(language string) string {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if alias, ok := languageAliases[language]; ok {
+		return alias
+	}
+	return language
+}
+
+// executeLanguage returns the language of a fence tagged with ExecuteTag, or
+// "" for any other tag.
+func executeLanguage(tag string) string {
+	tag = strings.ToLower(tag)
+	if tag == ExecuteTag {
+		return "python"
+	}
+	if language, ok := strings.CutPrefix(tag, ExecuteTag+"_"); ok {
+		return NormalizeLanguage(language)
+	}
+	return ""
+}
+
+// ExtractCodeBlocks returns the fenced code blocks in text that are tagged
+// with ExecuteTag for one of languages. Blocks tagged with a plain language
+// name are snippets the model shows to the user and are never returned.
+func ExtractCodeBlocks(text string, languages []string) []modelstypes.ExecutableCode {
+	var blocks []modelstypes.ExecutableCode
+	for _, m := range fencedBlockRe.FindAllStringSubmatch(text, -1) {
+		language := executeLanguage(m[1])
+		if language == "" || !slices.Contains(languages, language) {
+			continue
+		}
+		if code := strings.TrimSpace(m[2]); code != "" {
+			blocks = append(blocks, modelstypes.ExecutableCode{Language: language, Code: code})
+		}
+	}
+	return blocks
+}
+
+// Instruction tells the model how to ask for code in languages to be run.
+func Instruction(languages []string) string {
+	tags := make([]string, len(languages))
+	for i, language := range languages {
+		tags[i] = ExecuteTag + "_" + language
+	}
+	return fmt.Sprintf("You can run code to compute answers. Put code you want executed in a fenced block tagged %s, and you will receive its output. Code in blocks with any other tag is shown to the user and not run.",
+		strings.Join(tags, ", "))
+}
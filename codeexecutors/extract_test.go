@@ -0,0 +1,47 @@
+package codeexecutors
+
+import (
+	"reflect"
+	"testing"
+
+	modelstypes "github.com/KennethanCeyer/adk-go/models/types"
+)
+
+func TestExtractCodeBlocks(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []modelstypes.ExecutableCode
+	}{
+		{
+			name: "snippet shown to the user",
+			text: "Here is how:\n```python\nprint(1)\n```\n",
+		},
+		{
+			name: "untagged block",
+			text: "```\nprint(1)\n```",
+		},
+		{
+			name: "gemini tool_code",
+			text: "```tool_code\nprint(1)\n```",
+			want: []modelstypes.ExecutableCode{{Language: "python", Code: "print(1)"}},
+		},
+		{
+			name: "tagged language with alias",
+			text: "```tool_code_golang\npackage main\n```\n```go\nfunc shown() {}\n```",
+			want: []modelstypes.ExecutableCode{{Language: "go", Code: "package main"}},
+		},
+		{
+			name: "language the executor cannot run",
+			text: "```tool_code_ruby\nputs 1\n```",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ExtractCodeBlocks(tt.text, []string{"go", "python"})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractCodeBlocks() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package codeexecutors
+
+import (
+	"context"
+
+	modelstypes "github.com/KennethanCeyer/adk-go/models/types"
+)
+
+// CodeExecutor runs code blocks produced by a model. Failures of the code
+// itself are reported through the result's Outcome; a non-nil error means the
+// executor could not run the code at all.
+type CodeExecutor interface {
+	// Languages lists the lowercase language names the executor can run.
+	Languages() []string
+	Execute(ctx context.Context, code modelstypes.ExecutableCode) (*modelstypes.CodeExecutionResult, error)
+}
@@ -0,0 +1,169 @@
+package codeexecutors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/KennethanCeyer/adk-go/internal/process"
+	modelstypes "github.com/KennethanCeyer/adk-go/models/types"
+)
+
+const (
+	defaultTimeout        = 30 * time.Second
+	defaultMaxOutputBytes = 64 << 10 // 64 KiB per stream
+)
+
+// fileExtensions gives the source file name used for well-known languages.
+var fileExtensions = map[string]string{
+	"python":     ".py",
+	"javascript": ".js",
+	"bash":       ".sh",
+	"ruby":       ".rb",
+}
+
+type interpreter struct {
+	command string
+	args    []string
+}
+
+// LocalExecutor runs each code block as a local process in a fresh temporary
+// directory that is removed afterwards. Go is supported out of the box via
+// `go run`; other languages need an interpreter configured with
+// WithInterpreter.
+//
+// The code runs with the privileges of the current user. Only use it with
+// models and prompts you trust, or inside a sandbox.
+type LocalExecutor struct {
+	timeout        time.Duration
+	maxOutputBytes int
+	goBinary       string
+	interpreters   map[string]interpreter
+}
+
+type Option func(*LocalExecutor)
+
+// WithTimeout bounds the wall time of each execution. Defaults to 30s.
+func WithTimeout(d time.Duration) Option {
+	return func(e *LocalExecutor) { e.timeout = d }
+}
+
+// WithMaxOutputBytes caps the captured size of stdout and stderr each.
+func WithMaxOutputBytes(n int) Option {
+	return func(e *LocalExecutor) { e.maxOutputBytes = n }
+}
+
+// WithGoBinary sets the go command used for Go code. Defaults to "go".
+func WithGoBinary(path string) Option {
+	return func(e *LocalExecutor) { e.goBinary = path }
+}
+
+// WithInterpreter enables a language by running its code file with command,
+// e.g. WithInterpreter("python", "python3", "-I").
+func WithInterpreter(language, command string, args ...string) Option {
+	return func(e *LocalExecutor) {
+		e.interpreters[NormalizeLanguage(language)] = interpreter{command: command, args: args}
+	}
+}
+
+func NewLocalExecutor(opts ...Option) *LocalExecutor {
+	e := &LocalExecutor{
+		timeout:        defaultTimeout,
+		maxOutputBytes: defaultMaxOutputBytes,
+		goBinary:       "go",
+		interpreters:   make(map[string]interpreter),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	if e.timeout <= 0 {
+		e.timeout = defaultTimeout
+	}
+	if e.maxOutputBytes <= 0 {
+		e.maxOutputBytes = defaultMaxOutputBytes
+	}
+	return e
+}
+
+func (e *LocalExecutor) Languages() []string {
+	languages := []string{"go"}
+	for language := range e.interpreters {
+		if language != "go" {
+			languages = append(languages, language)
+		}
+	}
+	sort.Strings(languages[1:])
+	return languages
+}
+
+func (e *LocalExecutor) Execute(ctx context.Context, code modelstypes.ExecutableCode) (*modelstypes.CodeExecutionResult, error) {
+	language := NormalizeLanguage(code.Language)
+
+	dir, err := os.MkdirTemp("", "adk-code-*")
+	if err != nil {
+		return nil, fmt.Errorf("code executor: failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	var name string
+	var args []string
+	if interp, ok := e.interpreters[language]; ok {
+		file := filepath.Join(dir, "main"+fileExtensions[language])
+		if err := os.WriteFile(file, []byte(code.Code), 0o600); err != nil {
+			return nil, fmt.Errorf("code executor: failed to write code file: %w", err)
+		}
+		name = interp.command
+		args = append(append(args, interp.args...), file)
+	} else if language == "go" {
+		if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(code.Code), 0o600); err != nil {
+			return nil, fmt.Errorf("code executor: failed to write code file: %w", err)
+		}
+		name = e.goBinary
+		args = []string{"run", "main.go"}
+	} else {
+		return nil, fmt.Errorf("code executor: unsupported language '%s'", code.Language)
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(runCtx, name, args...)
+	cmd.Dir = dir
+	cmd.Env = environment(dir)
+	stdout := &process.CappedBuffer{Limit: e.maxOutputBytes}
+	stderr := &process.CappedBuffer{Limit: e.maxOutputBytes}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.WaitDelay = time.Second
+	process.Configure(cmd)
+
+	runErr := cmd.Run()
+	if runCtx.Err() == context.DeadlineExceeded {
+		return &modelstypes.CodeExecutionResult{
+			Outcome: modelstypes.OutcomeDeadlineExceeded,
+			Output:  fmt.Sprintf("Execution exceeded the %s timeout.\n%s%s", e.timeout, stdout, stderr),
+		}, nil
+	}
+	if runErr != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(runErr, &exitErr) {
+			return nil, fmt.Errorf("code executor: failed to run '%s': %w", name, runErr)
+		}
+		return &modelstypes.CodeExecutionResult{
+			Outcome: modelstypes.OutcomeFailed,
+			Output:  fmt.Sprintf("Exit code %d.\n%s%s", exitErr.ExitCode(), stdout, stderr),
+		}, nil
+	}
+	return &modelstypes.CodeExecutionResult{Outcome: modelstypes.OutcomeOK, Output: stdout.String()}, nil
+}
+
+func environment(dir string) []string {
+	env := process.Environ(process.PassEnv)
+	// Keep `go run` out of any module the temporary directory happens to be in.
+	return append(env, "GOFLAGS=", "GO111MODULE=auto", "TMPDIR="+dir)
+}
@@ -0,0 +1,197 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/KennethanCeyer/adk-go/agents"
+	"github.com/KennethanCeyer/adk-go/agents/interfaces"
+	"github.com/KennethanCeyer/adk-go/llmproviders"
+	modelstypes "github.com/KennethanCeyer/adk-go/models/types"
+	"github.com/KennethanCeyer/adk-go/tools"
+)
+
+type loader struct {
+	provider    llmproviders.LLMProvider
+	providerErr error
+}
+
+type Option func(*loader)
+
+// WithProvider sets the LLM provider used by the loaded agents. By default
+// a Gemini provider is created when the first agent needs one.
+func WithProvider(provider llmproviders.LLMProvider) Option {
+	return func(l *loader) { l.provider = provider }
+}
+
+// LoadFile loads the agent defined in a YAML or JSON file. Tools are looked
+// up by name in the tool registry, so they must be registered first.
+func LoadFile(path string, opts ...Option) (interfaces.Agent, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config: failed to read '%s': %w", path, err)
+	}
+	return Load(data, path, opts...)
+}
+
+// Load parses and builds the agent defined in data. file is only used in
+// error messages.
+func Load(data []byte, file string, opts ...Option) (interfaces.Agent, error) {
+	cfg, err := Parse(data, file)
+	if err != nil {
+		return nil, err
+	}
+	l := &loader{}
+	for _, opt := range opts {
+		opt(l)
+	}
+	c := &errorCollector{file: file}
+	agent := l.build(c, cfg)
+	if err := c.err(); err != nil {
+		return nil, err
+	}
+	if err := agents.Validate(agent); err != nil {
+		return nil, positionProblems(c, cfg, err)
+	}
+	return agent, nil
+}
+
+// positionProblems reports the problems agents.Validate found at the
+// definitions of the agents they are about, or at the root agent when the
+// agent is unknown.
+func positionProblems(c *errorCollector, root *AgentConfig, err error) error {
+	var validationErr *agents.ValidationError
+	if !errors.As(err, &validationErr) {
+		return fmt.Errorf("%s: %w", c.file, err)
+	}
+	byName := make(map[string]*AgentConfig)
+	var index func(cfg *AgentConfig)
+	index = func(cfg *AgentConfig) {
+		byName[cfg.Name] = cfg
+		for _, sub := range cfg.SubAgents {
+			index(sub)
+		}
+	}
+	index(root)
+	for i, problem := range validationErr.Problems {
+		cfg := root
+		if i < len(validationErr.Agents) && byName[validationErr.Agents[i]] != nil {
+			cfg = byName[validationErr.Agents[i]]
+		}
+		c.add(cfg.node, "%s", problem)
+	}
+	return c.err()
+}
+
+// DirEntry is the outcome of loading one file with LoadDir.
+type DirEntry struct {
+	Path  string
+	Name  string // The agent's name, or the file name without extension if loading failed
+	Agent interfaces.Agent
+	Err   error
+}
+
+// LoadDir loads every .yaml, .yml and .json file in dir, in name order. A
+// file that fails to load is reported in its entry and does not stop the
+// others from loading.
+func LoadDir(dir string, opts ...Option) ([]DirEntry, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("config: failed to read directory '%s': %w", dir, err)
+	}
+	var names []string
+	for _, f := range files {
+		switch strings.ToLower(filepath.Ext(f.Name())) {
+		case ".yaml", ".yml", ".json":
+			if !f.IsDir() {
+				names = append(names, f.Name())
+			}
+		}
+	}
+	sort.Strings(names)
+
+	entries := make([]DirEntry, 0, len(names))
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		entry := DirEntry{Path: path, Name: strings.TrimSuffix(name, filepath.Ext(name))}
+		entry.Agent, entry.Err = LoadFile(path, opts...)
+		if entry.Agent != nil {
+			entry.Name = entry.Agent.GetName()
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func (l *loader) getProvider(c *errorCollector, cfg *AgentConfig) llmproviders.LLMProvider {
+	if l.provider == nil && l.providerErr == nil {
+		l.provider, l.providerErr = llmproviders.NewGeminiLLMProvider()
+		if l.providerErr != nil {
+			c.add(cfg.fields["model"], "cannot create the model provider for agent '%s': %v", cfg.Name, l.providerErr)
+		}
+	}
+	return l.provider
+}
+
+func (l *loader) build(c *errorCollector, cfg *AgentConfig) interfaces.Agent {
+	var subAgents []interfaces.Agent
+	for _, sub := range cfg.SubAgents {
+		if agent := l.build(c, sub); agent != nil {
+			subAgents = append(subAgents, agent)
+		}
+	}
+
+	var instruction *modelstypes.Message
+	if cfg.Instruction != "" {
+		text := cfg.Instruction
+		instruction = &modelstypes.Message{Role: "system", Parts: []modelstypes.Part{{Text: &text}}}
+	}
+
+	switch cfg.Type {
+	case TypeSequential:
+		return agents.NewSequentialAgent(cfg.Name, cfg.Description, subAgents)
+
+	case TypeLoop:
+		return agents.NewLoopAgent(cfg.Name, cfg.Description, subAgents, cfg.MaxIterations, nil)
+
+	case TypeParallel:
+		var provider llmproviders.LLMProvider
+		if cfg.Synthesis == SynthesisLLM {
+			provider = l.getProvider(c, cfg)
+		}
+		agent := agents.NewParallelAgent(cfg.Name, cfg.Description, cfg.Model, instruction, provider, subAgents)
+		switch cfg.ErrorPolicy {
+		case PolicyBestEffort:
+			agent.ErrorPolicy = agents.BestEffort
+		case PolicyQuorum:
+			agent.ErrorPolicy = agents.Quorum
+			agent.Quorum = cfg.Quorum
+		}
+		if cfg.Synthesis == SynthesisKeyed {
+			agent.Synthesizer = agents.KeyedResults
+		}
+		return agent
+
+	default:
+		var agentTools []tools.Tool
+		for i, name := range cfg.Tools {
+			tool, found := tools.GetTool(name)
+			if !found {
+				c.add(cfg.fields["tools"].Content[i], "unknown tool '%s'; registered tools: [%s]", name, strings.Join(tools.ListTools(), ", "))
+				continue
+			}
+			agentTools = append(agentTools, tool)
+		}
+		agent := agents.NewBaseLlmAgent(cfg.Name, cfg.Description, cfg.Model, instruction, l.getProvider(c, cfg), agentTools).(*agents.BaseLlmAgent)
+		agent.OutputKey = cfg.OutputKey
+		agent.GenerationConfig = cfg.GenerationConfig
+		if err := agent.AddSubAgents(subAgents...); err != nil {
+			c.add(cfg.fields["sub_agents"], "%v", err)
+		}
+		return agent
+	}
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	modelstypes "github.com/KennethanCeyer/adk-go/models/types"
+	"github.com/KennethanCeyer/adk-go/tools"
+)
+
+type stubProvider struct{}
+
+func (stubProvider) GenerateContent(context.Context, string, *modelstypes.Message, []tools.Tool, []modelstypes.Message, modelstypes.Message) (*modelstypes.Message, error) {
+	return nil, nil
+}
+
+type echoTool struct{}
+
+func (echoTool) Name() string        { return "config_test_echo" }
+func (echoTool) Description() string { return "Echoes its input." }
+func (echoTool) Parameters() any     { return map[string]any{"type": "object"} }
+func (echoTool) Execute(_ context.Context, args any) (any, error) {
+	return args, nil
+}
+
+func init() {
+	if err := tools.RegisterTool(echoTool{}); err != nil {
+		panic(err)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want []string // Each error line, in order
+	}{
+		{
+			name: "unknown field",
+			yaml: `name: helper
+model: gemini
+temprature: 0.2
+`,
+			want: []string{"agent.yaml:3:1: unknown field 'temprature' in llm agent; valid fields are: "},
+		},
+		{
+			name: "wrong type",
+			yaml: `name: retry
+type: loop
+max_iterations: many
+sub_agents:
+  - name: step
+    model: gemini
+`,
+			want: []string{"agent.yaml:3:17: 'max_iterations' must be an integer, got string 'many'"},
+		},
+		{
+			name: "duplicate names",
+			yaml: `name: pipeline
+type: sequential
+sub_agents:
+  - name: step
+    model: gemini
+  - name: step
+    model: gemini
+`,
+			want: []string{"agent.yaml:6:11: duplicate agent name 'step'; it is already used at line 4"},
+		},
+		{
+			name: "bad quorum",
+			yaml: `name: fan
+type: parallel
+error_policy: quorum
+quorum: 3
+sub_agents:
+  - name: a
+    model: gemini
+  - name: b
+    model: gemini
+`,
+			want: []string{"agent.yaml:4:9: error_policy 'quorum' needs a 'quorum' between 1 and the number of sub-agents (2)"},
+		},
+		{
+			name: "unregistered tool",
+			yaml: `name: helper
+model: gemini
+tools:
+  - config_test_echo
+  - no_such_tool
+`,
+			want: []string{"agent.yaml:5:5: unknown tool 'no_such_tool'; registered tools: ["},
+		},
+		{
+			name: "tree problem",
+			yaml: `name: pipeline
+type: sequential
+sub_agents:
+  - name: helper
+    model: gemini
+    tools: [config_test_echo, config_test_echo]
+`,
+			want: []string{"agent.yaml:4:5: llm agent 'helper' has more than one tool named 'config_test_echo'"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load([]byte(tt.yaml), "agent.yaml", WithProvider(stubProvider{}))
+			if err == nil {
+				t.Fatal("Load succeeded, want an error")
+			}
+			lines := strings.Split(err.Error(), "\n")
+			if len(lines) != len(tt.want) {
+				t.Fatalf("got %d errors, want %d:\n%v", len(lines), len(tt.want), err)
+			}
+			for i, want := range tt.want {
+				if !strings.HasPrefix(lines[i], want) {
+					t.Errorf("error %d = %q, want prefix %q", i, lines[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadValid(t *testing.T) {
+	agent, err := Load([]byte(`name: helper
+model: gemini
+tools: [config_test_echo]
+`), "agent.yaml", WithProvider(stubProvider{}))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if agent.GetName() != "helper" {
+		t.Errorf("name = %q", agent.GetName())
+	}
+}
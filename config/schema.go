@@ -0,0 +1,391 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	modelstypes "github.com/KennethanCeyer/adk-go/models/types"
+	"gopkg.in/yaml.v3"
+)
+
+// Agent types.
+const (
+	TypeLLM        = "llm"
+	TypeSequential = "sequential"
+	TypeParallel   = "parallel"
+	TypeLoop       = "loop"
+)
+
+// Error policies and synthesis modes of parallel agents.
+const (
+	PolicyFailFast   = "fail_fast"
+	PolicyBestEffort = "best_effort"
+	PolicyQuorum     = "quorum"
+
+	SynthesisLLM   = "llm"
+	SynthesisKeyed = "keyed"
+)
+
+var (
+	agentTypes     = []string{TypeLLM, TypeSequential, TypeParallel, TypeLoop}
+	errorPolicies  = []string{PolicyFailFast, PolicyBestEffort, PolicyQuorum}
+	synthesisModes = []string{SynthesisLLM, SynthesisKeyed}
+)
+
+var nameRe = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]*$`)
+
+// AgentConfig is the declarative form of an agent. A file holds one root
+// agent; workflow agents nest their sub-agents:
+//
+//	name: trip_planner
+//	type: parallel
+//	model: gemini-2.5-flash
+//	instruction: Combine the flight and hotel options into one travel plan.
+//	sub_agents:
+//	  - name: flight_agent
+//	    model: gemini-2.5-flash
+//	    instruction: Find flights to {city?} with the find_flights tool.
+//	    tools: [find_flights]
+//	    generation_config: {temperature: 0.2}
+//	  - name: hotel_agent
+//	    model: gemini-2.5-flash
+//	    instruction: Find hotels with the find_hotels tool.
+//	    tools: [find_hotels]
+type AgentConfig struct {
+	Name        string
+	Type        string // One of the Type constants; defaults to "llm"
+	Description string
+
+	// LLM agents, and parallel agents that synthesize with a model.
+	Model       string
+	Instruction string
+
+	// LLM agents only.
+	Tools            []string // Names of tools in the tool registry
+	OutputKey        string
+	GenerationConfig *modelstypes.GenerationConfig
+
+	// Sub-agents of workflow agents, or transfer targets of LLM agents.
+	SubAgents []*AgentConfig
+
+	// Loop agents only.
+	MaxIterations int
+
+	// Parallel agents only.
+	ErrorPolicy string // One of the Policy constants; defaults to "fail_fast"
+	Quorum      int
+	Synthesis   string // "llm" or "keyed"; defaults to "llm" if a model is set
+
+	node   *yaml.Node
+	fields map[string]*yaml.Node // Value nodes by key, for error positions
+}
+
+// fieldTypes lists the fields and the agent types that accept them.
+var fieldTypes = map[string][]string{
+	"name":              agentTypes,
+	"type":              agentTypes,
+	"description":       agentTypes,
+	"sub_agents":        agentTypes,
+	"model":             {TypeLLM, TypeParallel},
+	"instruction":       {TypeLLM, TypeParallel},
+	"tools":             {TypeLLM},
+	"output_key":        {TypeLLM},
+	"generation_config": {TypeLLM},
+	"max_iterations":    {TypeLoop},
+	"error_policy":      {TypeParallel},
+	"quorum":            {TypeParallel},
+	"synthesis":         {TypeParallel},
+}
+
+var generationConfigFields = []string{"temperature", "top_p", "top_k", "max_output_tokens", "stop_sequences", "response_mime_type"}
+
+// Parse validates an agent config document, given in YAML or JSON, and
+// returns the root agent. file is only used in error messages. All problems
+// are reported together as Errors, each with its line and column.
+func Parse(data []byte, file string) (*AgentConfig, error) {
+	c := &errorCollector{file: file}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		c.add(nil, "invalid YAML: %v", err)
+		return nil, c.err()
+	}
+	if doc.Kind == 0 || len(doc.Content) == 0 {
+		c.add(nil, "the file is empty; expected an agent definition")
+		return nil, c.err()
+	}
+	cfg := parseAgent(c, doc.Content[0])
+	if cfg != nil {
+		checkUniqueNames(c, cfg, make(map[string]*AgentConfig))
+	}
+	if err := c.err(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func parseAgent(c *errorCollector, node *yaml.Node) *AgentConfig {
+	if node.Kind != yaml.MappingNode {
+		c.add(node, "expected an agent definition (a mapping with name, type, ...), got %s", describe(node))
+		return nil
+	}
+	cfg := &AgentConfig{Type: TypeLLM, node: node, fields: make(map[string]*yaml.Node)}
+
+	// The type decides which other fields are valid, so it is read first.
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == "type" {
+			if t, ok := scalarString(c, "type", node.Content[i+1]); ok {
+				if !slices.Contains(agentTypes, t) {
+					c.add(node.Content[i+1], "unknown agent type '%s'; expected one of: %s", t, strings.Join(agentTypes, ", "))
+					return nil
+				}
+				cfg.Type = t
+			}
+		}
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, value := node.Content[i], node.Content[i+1]
+		key := keyNode.Value
+		types, known := fieldTypes[key]
+		switch {
+		case !known:
+			c.add(keyNode, "unknown field '%s' in %s agent; valid fields are: %s", key, cfg.Type, strings.Join(validFields(cfg.Type), ", "))
+			continue
+		case !slices.Contains(types, cfg.Type):
+			c.add(keyNode, "field '%s' is not valid for %s agents; it applies to: %s", key, cfg.Type, strings.Join(types, ", "))
+			continue
+		case cfg.fields[key] != nil:
+			c.add(keyNode, "duplicate field '%s'", key)
+			continue
+		}
+		cfg.fields[key] = value
+
+		switch key {
+		case "name":
+			cfg.Name, _ = scalarString(c, key, value)
+		case "description":
+			cfg.Description, _ = scalarString(c, key, value)
+		case "model":
+			cfg.Model, _ = scalarString(c, key, value)
+		case "instruction":
+			cfg.Instruction, _ = scalarString(c, key, value)
+		case "output_key":
+			cfg.OutputKey, _ = scalarString(c, key, value)
+		case "tools":
+			cfg.Tools, _ = stringList(c, key, value)
+		case "generation_config":
+			cfg.GenerationConfig = parseGenerationConfig(c, value)
+		case "max_iterations":
+			cfg.MaxIterations, _ = scalarInt(c, key, value)
+		case "error_policy":
+			cfg.ErrorPolicy, _ = scalarString(c, key, value)
+		case "quorum":
+			cfg.Quorum, _ = scalarInt(c, key, value)
+		case "synthesis":
+			cfg.Synthesis, _ = scalarString(c, key, value)
+		case "sub_agents":
+			if value.Kind != yaml.SequenceNode {
+				c.add(value, "'sub_agents' must be a list of agent definitions, got %s", describe(value))
+				continue
+			}
+			for _, item := range value.Content {
+				if sub := parseAgent(c, item); sub != nil {
+					cfg.SubAgents = append(cfg.SubAgents, sub)
+				}
+			}
+		}
+	}
+
+	validateAgent(c, cfg)
+	return cfg
+}
+
+func validateAgent(c *errorCollector, cfg *AgentConfig) {
+	switch {
+	case cfg.fields["name"] == nil:
+		c.add(cfg.node, "missing required field 'name'")
+	case cfg.fields["name"].Tag == "!!str" && !nameRe.MatchString(cfg.Name):
+		c.add(cfg.fields["name"], "invalid agent name '%s'; use letters, digits, '_' and '-', starting with a letter or '_'", cfg.Name)
+	}
+
+	switch cfg.Type {
+	case TypeLLM:
+		if cfg.Model == "" {
+			c.add(cfg.node, "missing required field 'model' for llm agent '%s'", cfg.Name)
+		}
+	case TypeSequential, TypeParallel, TypeLoop:
+		if n := cfg.fields["sub_agents"]; n == nil || (n.Kind == yaml.SequenceNode && len(n.Content) == 0) {
+			c.add(cfg.node, "%s agent '%s' needs at least one entry in 'sub_agents'", cfg.Type, cfg.Name)
+		}
+	}
+
+	if cfg.Type == TypeLoop {
+		if node := cfg.fields["max_iterations"]; node == nil {
+			c.add(cfg.node, "missing required field 'max_iterations' for loop agent '%s'", cfg.Name)
+		} else if node.Tag == "!!int" && cfg.MaxIterations <= 0 {
+			c.add(node, "'max_iterations' must be positive, got %d", cfg.MaxIterations)
+		}
+	}
+
+	if cfg.Type == TypeParallel {
+		if cfg.ErrorPolicy == "" {
+			cfg.ErrorPolicy = PolicyFailFast
+		} else if !slices.Contains(errorPolicies, cfg.ErrorPolicy) {
+			c.add(cfg.fields["error_policy"], "unknown error_policy '%s'; expected one of: %s", cfg.ErrorPolicy, strings.Join(errorPolicies, ", "))
+		}
+		if cfg.ErrorPolicy == PolicyQuorum && (cfg.Quorum <= 0 || cfg.Quorum > len(cfg.SubAgents)) {
+			node := cfg.fields["quorum"]
+			if node == nil {
+				node = cfg.node
+			}
+			c.add(node, "error_policy 'quorum' needs a 'quorum' between 1 and the number of sub-agents (%d)", len(cfg.SubAgents))
+		} else if cfg.ErrorPolicy != PolicyQuorum && cfg.fields["quorum"] != nil {
+			c.add(cfg.fields["quorum"], "'quorum' only applies with error_policy 'quorum'")
+		}
+
+		if cfg.Synthesis == "" {
+			cfg.Synthesis = SynthesisKeyed
+			if cfg.Model != "" {
+				cfg.Synthesis = SynthesisLLM
+			}
+		} else if !slices.Contains(synthesisModes, cfg.Synthesis) {
+			c.add(cfg.fields["synthesis"], "unknown synthesis '%s'; expected one of: %s", cfg.Synthesis, strings.Join(synthesisModes, ", "))
+		}
+		if cfg.Synthesis == SynthesisLLM && cfg.Model == "" {
+			c.add(cfg.fields["synthesis"], "synthesis 'llm' needs a 'model' on parallel agent '%s'", cfg.Name)
+		}
+	}
+}
+
+func checkUniqueNames(c *errorCollector, cfg *AgentConfig, seen map[string]*AgentConfig) {
+	if cfg.Name != "" {
+		if first, exists := seen[cfg.Name]; exists {
+			c.add(cfg.fields["name"], "duplicate agent name '%s'; it is already used at line %d", cfg.Name, first.node.Line)
+		} else {
+			seen[cfg.Name] = cfg
+		}
+	}
+	for _, sub := range cfg.SubAgents {
+		checkUniqueNames(c, sub, seen)
+	}
+}
+
+func parseGenerationConfig(c *errorCollector, node *yaml.Node) *modelstypes.GenerationConfig {
+	if node.Kind != yaml.MappingNode {
+		c.add(node, "'generation_config' must be a mapping, got %s", describe(node))
+		return nil
+	}
+	config := &modelstypes.GenerationConfig{}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, value := node.Content[i], node.Content[i+1]
+		key := "generation_config." + keyNode.Value
+		switch keyNode.Value {
+		case "temperature", "top_p":
+			f, ok := scalarFloat(c, key, value)
+			if !ok {
+				continue
+			}
+			if keyNode.Value == "temperature" {
+				config.Temperature = &f
+			} else {
+				config.TopP = &f
+			}
+		case "top_k", "max_output_tokens":
+			n, ok := scalarInt(c, key, value)
+			if !ok {
+				continue
+			}
+			n32 := int32(n)
+			if keyNode.Value == "top_k" {
+				config.TopK = &n32
+			} else {
+				config.MaxOutputTokens = &n32
+			}
+		case "stop_sequences":
+			config.StopSequences, _ = stringList(c, key, value)
+		case "response_mime_type":
+			config.ResponseMIMEType, _ = scalarString(c, key, value)
+		default:
+			c.add(keyNode, "unknown field '%s' in generation_config; valid fields are: %s", keyNode.Value, strings.Join(generationConfigFields, ", "))
+		}
+	}
+	return config
+}
+
+func validFields(agentType string) []string {
+	var fields []string
+	for field, types := range fieldTypes {
+		if slices.Contains(types, agentType) {
+			fields = append(fields, field)
+		}
+	}
+	slices.Sort(fields)
+	return fields
+}
+
+func scalarString(c *errorCollector, key string, node *yaml.Node) (string, bool) {
+	if node.Kind != yaml.ScalarNode || node.Tag != "!!str" {
+		c.add(node, "'%s' must be a string, got %s", key, describe(node))
+		return "", false
+	}
+	return node.Value, true
+}
+
+func scalarInt(c *errorCollector, key string, node *yaml.Node) (int, bool) {
+	var n int
+	if node.Kind != yaml.ScalarNode || node.Tag != "!!int" || node.Decode(&n) != nil {
+		c.add(node, "'%s' must be an integer, got %s", key, describe(node))
+		return 0, false
+	}
+	return n, true
+}
+
+func scalarFloat(c *errorCollector, key string, node *yaml.Node) (float32, bool) {
+	var f float64
+	if node.Kind != yaml.ScalarNode || (node.Tag != "!!float" && node.Tag != "!!int") || node.Decode(&f) != nil {
+		c.add(node, "'%s' must be a number, got %s", key, describe(node))
+		return 0, false
+	}
+	return float32(f), true
+}
+
+func stringList(c *errorCollector, key string, node *yaml.Node) ([]string, bool) {
+	if node.Kind != yaml.SequenceNode {
+		c.add(node, "'%s' must be a list of strings, got %s", key, describe(node))
+		return nil, false
+	}
+	list := make([]string, 0, len(node.Content))
+	ok := true
+	for _, item := range node.Content {
+		s, itemOK := scalarString(c, key+" item", item)
+		ok = ok && itemOK
+		list = append(list, s)
+	}
+	return list, ok
+}
+
+// describe names the kind of a node for error messages.
+func describe(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "a mapping"
+	case yaml.SequenceNode:
+		return "a list"
+	case yaml.AliasNode:
+		return "an alias"
+	case yaml.ScalarNode:
+		switch node.Tag {
+		case "!!str":
+			return fmt.Sprintf("string '%s'", node.Value)
+		case "!!null":
+			return "null"
+		case "!!bool":
+			return fmt.Sprintf("boolean %s", node.Value)
+		default:
+			return fmt.Sprintf("'%s'", node.Value)
+		}
+	}
+	return "an empty value"
+}
@@ -1,13 +1,20 @@
 package file_based_chat
 
 import (
+	"os"
+
 	"github.com/KennethanCeyer/adk-go/agents"
 	"github.com/KennethanCeyer/adk-go/examples"
 	"github.com/KennethanCeyer/adk-go/llmproviders"
 	"github.com/KennethanCeyer/adk-go/models/types"
 	"github.com/KennethanCeyer/adk-go/tools"
+	"github.com/KennethanCeyer/adk-go/tools/fs"
 )
 
+// workspaceRootEnv names the directory the agent may access. It defaults to
+// the current working directory.
+const workspaceRootEnv = "FILE_CHAT_ROOT"
+
 func init() {
 	provider, err := llmproviders.NewGeminiLLMProvider()
 	if err != nil {
@@ -16,7 +23,18 @@ func init() {
 		return
 	}
 
-	systemText := "You are a helpful assistant that specializes in reading and writing local files. When the conversation starts with a simple greeting, introduce yourself and your capabilities. For example: 'Hello! I can read and write files for you. You can ask me to do things like: \\\"read notes.txt\\\" or \\\"write 'Hello World' to a new file named welcome.txt\\\". What would you like to do?'. For other requests, use the provided tools to manage files as requested by the user."
+	root := os.Getenv(workspaceRootEnv)
+	if root == "" {
+		root = "."
+	}
+	// Changes to the workspace only go ahead once the user approves them.
+	workspace, err := fs.NewToolset(root, fs.WithWriteConfirmation(tools.ConfirmAlways))
+	if err != nil {
+		examples.RegisterAgent("file_based_chat", nil, err)
+		return
+	}
+
+	systemText := "You are a helpful assistant that specializes in reading and writing local files. When the conversation starts with a simple greeting, introduce yourself and your capabilities. For example: 'Hello! I can read and write files for you. You can ask me to do things like: \\\"read notes.txt\\\" or \\\"write 'Hello World' to a new file named welcome.txt\\\". What would you like to do?'. For other requests, use the provided tools to manage files as requested by the user. All paths are relative to your workspace directory; you cannot access files outside of it. Prefer edit_file for small changes to existing files."
 	systemInstruction := &types.Message{
 		Role: "system",
 		Parts: []types.Part{
@@ -26,11 +44,11 @@ func init() {
 
 	agent := agents.NewBaseLlmAgent(
 		"file_based_chat",
-		"An agent that can read and write local files.",
+		"An agent that can read, search and edit files in a local workspace.",
 		"gemini-2.5-flash",
 		systemInstruction,
 		provider,
-		[]tools.Tool{NewReadFileTool(), NewWriteFileTool()},
+		workspace.Tools(),
 	)
 
 	examples.RegisterAgent("file_based_chat", agent, nil)
@@ -1,8 +1,6 @@
 package looping_guesser
 
 import (
-	"strings"
-
 	"github.com/KennethanCeyer/adk-go/agents"
 	"github.com/KennethanCeyer/adk-go/agents/interfaces"
 	"github.com/KennethanCeyer/adk-go/examples"
@@ -39,19 +37,11 @@ If the tool response indicates you are 'correct', your final response MUST be "I
 	loopingGuesserAgent := agents.NewLoopAgent(
 		"looping_guesser",
 		"An agent that plays a number guessing game automatically by looping.",
-		[]interfaces.LlmAgent{guesserSubAgent},
+		[]interfaces.Agent{guesserSubAgent},
 		10,
-		func(latestResponse *modelstypes.Message) bool {
-			if latestResponse != nil {
-				for _, part := range latestResponse.Parts {
-					if part.Text != nil && strings.Contains(*part.Text, "I win!") {
-						return true
-					}
-				}
-			}
-			return false
-		},
+		nil, // check_guess escalates once the guess is correct, which ends the loop.
 	)
+	loopingGuesserAgent.ResultKey = "guessing_result"
 
 	examples.RegisterAgent("looping_guesser", loopingGuesserAgent, nil)
 }
@@ -51,7 +51,7 @@ func init() {
 		"gemini-2.5-flash",
 		synthesisInstruction,
 		geminiProvider,
-		[]interfaces.LlmAgent{flightAgent, hotelAgent},
+		[]interfaces.Agent{flightAgent, hotelAgent},
 	)
 
 	examples.RegisterAgent("parallel_trip_planner", tripPlannerAgent, nil)
@@ -5,6 +5,7 @@ import (
 	"sort"
 	"sync"
 
+	"github.com/KennethanCeyer/adk-go/agents"
 	"github.com/KennethanCeyer/adk-go/agents/interfaces"
 )
 
@@ -15,14 +16,21 @@ type AgentDefinition struct {
 
 var (
 	mu               sync.RWMutex
-	agents           = make(map[string]interfaces.LlmAgent)
+	registered       = make(map[string]interfaces.Agent)
 	agentDefinitions = make(map[string]*AgentDefinition)
 )
 
-func RegisterAgent(name string, agent interfaces.LlmAgent, err error) {
+// RegisterAgent makes agent available under name. An agent that failed to
+// initialize, or whose tree does not pass agents.Validate, is only recorded
+// with its error.
+func RegisterAgent(name string, agent interfaces.Agent, err error) {
 	mu.Lock()
 	defer mu.Unlock()
 
+	if err == nil && agent != nil {
+		err = agents.Validate(agent)
+	}
+
 	var errMsg string
 	if err != nil {
 		errMsg = err.Error()
@@ -35,22 +43,22 @@ func RegisterAgent(name string, agent interfaces.LlmAgent, err error) {
 	}
 
 	if agent != nil && err == nil {
-		agents[name] = agent
+		registered[name] = agent
 	}
 }
 
-func GetAgent(name string) (interfaces.LlmAgent, bool) {
+func GetAgent(name string) (interfaces.Agent, bool) {
 	mu.RLock()
 	defer mu.RUnlock()
-	agent, found := agents[name]
+	agent, found := registered[name]
 	return agent, found
 }
 
 func ListAgents() []string {
 	mu.RLock()
 	defer mu.RUnlock()
-	names := make([]string, 0, len(agents))
-	for name := range agents {
+	names := make([]string, 0, len(registered))
+	for name := range registered {
 		names = append(names, name)
 	}
 	sort.Strings(names)
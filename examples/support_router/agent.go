@@ -0,0 +1,51 @@
+package support_router
+
+import (
+	"github.com/KennethanCeyer/adk-go/agents"
+	"github.com/KennethanCeyer/adk-go/examples"
+	"github.com/KennethanCeyer/adk-go/llmproviders"
+	modelstypes "github.com/KennethanCeyer/adk-go/models/types"
+)
+
+func init() {
+	provider, err := llmproviders.NewGeminiLLMProvider()
+	if err != nil {
+		examples.RegisterAgent("support_router", nil, err)
+		return
+	}
+
+	newSupportAgent := func(name, description, instructionText string) *agents.BaseLlmAgent {
+		systemInstruction := &modelstypes.Message{Parts: []modelstypes.Part{{Text: &instructionText}}}
+		return agents.NewBaseLlmAgent(name, description, "gemini-2.5-flash", systemInstruction, provider, nil).(*agents.BaseLlmAgent)
+	}
+
+	billingAgent := newSupportAgent(
+		"billing_agent",
+		"Answers questions about invoices, payments and refunds.",
+		"You are a billing specialist. Help the user with invoices, payments, refunds and subscription plans. Be precise and ask for an invoice number when it would help.",
+	)
+	techAgent := newSupportAgent(
+		"tech_support_agent",
+		"Troubleshoots technical problems.",
+		"You are a technical support engineer. Help the user troubleshoot their problem step by step, asking for error messages and versions when needed.",
+	)
+	generalAgent := newSupportAgent(
+		"general_agent",
+		"Handles greetings and everything else.",
+		"You are the front desk of a support team. When the conversation starts with a simple greeting, introduce yourself and explain that you can help with billing and technical questions. Answer other questions briefly and politely.",
+	)
+
+	router := agents.NewSwitchAgent(
+		"support_router",
+		"Routes each support request to the billing or technical support agent.",
+		[]agents.Branch{
+			{Name: "billing", Description: "invoices, payments, refunds, pricing and subscriptions", Agent: billingAgent},
+			{Name: "technical", Description: "errors, crashes, installation and other technical problems", Agent: techAgent},
+		},
+		generalAgent,
+	)
+	router.Provider = provider
+	router.ModelID = "gemini-2.5-flash"
+
+	examples.RegisterAgent("support_router", router, nil)
+}
@@ -0,0 +1,49 @@
+// Package process holds the helpers shared by the packages that run local
+// commands on behalf of a model: environment scrubbing, output capping and
+// process group handling.
+package process
+
+import (
+	"bytes"
+	"os"
+)
+
+// PassEnv lists the environment variables inherited by commands by default.
+// Everything else, including credentials such as GEMINI_API_KEY, is scrubbed.
+var PassEnv = []string{"PATH", "HOME", "LANG", "LC_ALL", "TMPDIR", "GOPATH", "GOCACHE", "GOMODCACHE", "GOTOOLCHAIN"}
+
+// Environ returns the NAME=value entries of the named variables that are set
+// in the current process.
+func Environ(names []string) []string {
+	var env []string
+	for _, name := range names {
+		if val, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+val)
+		}
+	}
+	return env
+}
+
+// CappedBuffer keeps the first Limit bytes written to it and discards the
+// rest, recording that it did so in Truncated.
+type CappedBuffer struct {
+	Limit     int
+	Truncated bool
+	buf       bytes.Buffer
+}
+
+func (b *CappedBuffer) Write(p []byte) (int, error) {
+	remaining := b.Limit - b.buf.Len()
+	if remaining <= 0 {
+		b.Truncated = len(p) > 0 || b.Truncated
+		return len(p), nil
+	}
+	if len(p) > remaining {
+		b.buf.Write(p[:remaining])
+		b.Truncated = true
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+func (b *CappedBuffer) String() string { return b.buf.String() }
@@ -76,6 +76,16 @@ func parseSchema(schemaMap map[string]any) *genai.Schema {
 		schema.Required = req
 	}
 
+	if enum, ok := schemaMap["enum"].([]any); ok {
+		for _, e := range enum {
+			if eStr, eOk := e.(string); eOk {
+				schema.Enum = append(schema.Enum, eStr)
+			}
+		}
+	} else if enum, ok := schemaMap["enum"].([]string); ok {
+		schema.Enum = enum
+	}
+
 	return schema
 }
 
@@ -119,12 +129,16 @@ func convertADKMessagesToGenaiContent(messages []modelstypes.Message) []*genai.C
 					continue
 				}
 			} else if p.FunctionResponse != nil {
-				if respMap, ok := p.FunctionResponse.Response.(map[string]any); ok {
+				if respMap, err := tools.NormalizeResult(p.FunctionResponse.Response); err == nil {
 					genaiPart = genai.FunctionResponse{Name: p.FunctionResponse.Name, Response: respMap}
 				} else {
-					log.Printf("Warning: FunctionResponse.Response for tool '%s' is not a map[string]any, but %T. Skipping part.", p.FunctionResponse.Name, p.FunctionResponse.Response)
+					log.Printf("Warning: FunctionResponse.Response for tool '%s' could not be normalized: %v. Skipping part.", p.FunctionResponse.Name, err)
 					continue
 				}
+			} else if p.ExecutableCode != nil {
+				genaiPart = convertExecutableCode(adkMessage.Role, p.ExecutableCode)
+			} else if p.CodeExecutionResult != nil {
+				genaiPart = convertCodeExecutionResult(adkMessage.Role, p.CodeExecutionResult)
 			} else { continue }
 			content.Parts = append(content.Parts, genaiPart)
 		}
@@ -135,6 +149,55 @@ func convertADKMessagesToGenaiContent(messages []modelstypes.Message) []*genai.C
 	return genaiContents
 }
 
+// convertExecutableCode passes Python code written by the model through as
+// native executable code. Other languages, and code in non-model turns, are
+// sent as fenced text since Gemini has no representation for them.
+func convertExecutableCode(role string, code *modelstypes.ExecutableCode) genai.Part {
+	if role == "model" && code.Language == "python" {
+		return &genai.ExecutableCode{Language: genai.ExecutableCodePython, Code: code.Code}
+	}
+	return genai.Text(fmt.Sprintf("```%s\n%s\n```", code.Language, code.Code))
+}
+
+// convertCodeExecutionResult keeps results produced by Gemini's own code
+// execution native. Results of locally executed code are fed back to the model
+// as text, because Gemini only accepts native results right after the
+// executable code part they belong to.
+func convertCodeExecutionResult(role string, result *modelstypes.CodeExecutionResult) genai.Part {
+	if role == "model" {
+		outcome := genai.CodeExecutionResultOutcomeUnspecified
+		switch result.Outcome {
+		case modelstypes.OutcomeOK:
+			outcome = genai.CodeExecutionResultOutcomeOK
+		case modelstypes.OutcomeFailed:
+			outcome = genai.CodeExecutionResultOutcomeFailed
+		case modelstypes.OutcomeDeadlineExceeded:
+			outcome = genai.CodeExecutionResultOutcomeDeadlineExceeded
+		}
+		return &genai.CodeExecutionResult{Outcome: outcome, Output: result.Output}
+	}
+	return genai.Text(fmt.Sprintf("Code execution result (%s):\n```tool_outputs\n%s\n```", result.Outcome, result.Output))
+}
+
+func convertGenaiExecutableCode(code *genai.ExecutableCode) *modelstypes.ExecutableCode {
+	language := ""
+	if code.Language == genai.ExecutableCodePython {
+		language = "python"
+	}
+	return &modelstypes.ExecutableCode{Language: language, Code: code.Code}
+}
+
+func convertGenaiCodeExecutionResult(result *genai.CodeExecutionResult) *modelstypes.CodeExecutionResult {
+	outcome := modelstypes.OutcomeFailed
+	switch result.Outcome {
+	case genai.CodeExecutionResultOutcomeOK:
+		outcome = modelstypes.OutcomeOK
+	case genai.CodeExecutionResultOutcomeDeadlineExceeded:
+		outcome = modelstypes.OutcomeDeadlineExceeded
+	}
+	return &modelstypes.CodeExecutionResult{Outcome: outcome, Output: result.Output}
+}
+
 func convertGenaiCandidateToADKMessage(candidate *genai.Candidate) *modelstypes.Message {
 	adkMessage := &modelstypes.Message{Role: "model"}
 	if candidate == nil { text := "Error: LLM candidate was nil."; adkMessage.Parts = []modelstypes.Part{{Text: &text}}; return adkMessage }
@@ -150,6 +213,14 @@ func convertGenaiCandidateToADKMessage(candidate *genai.Candidate) *modelstypes.
 		case genai.FunctionCall:
 			// The genai.FunctionCall.Args is map[string]any, so no assertion is needed.
 			adkPart.FunctionCall = &modelstypes.FunctionCall{Name: v.Name, Args: v.Args}
+		case *genai.ExecutableCode:
+			adkPart.ExecutableCode = convertGenaiExecutableCode(v)
+		case genai.ExecutableCode:
+			adkPart.ExecutableCode = convertGenaiExecutableCode(&v)
+		case *genai.CodeExecutionResult:
+			adkPart.CodeExecutionResult = convertGenaiCodeExecutionResult(v)
+		case genai.CodeExecutionResult:
+			adkPart.CodeExecutionResult = convertGenaiCodeExecutionResult(&v)
 		default: unsupportedText := fmt.Sprintf("[LLM Part Type %T]", v); adkPart.Text = &unsupportedText
 		}
 		adkMessage.Parts = append(adkMessage.Parts, adkPart)
@@ -174,6 +245,9 @@ func (g *GeminiLLMProvider) GenerateContent(
 		model.SystemInstruction = &genai.Content{Parts: []genai.Part{genai.Text(*systemInstruction.Parts[0].Text)}}
 	}
 	if len(tools) > 0 { model.Tools = convertADKToolsToGenaiTools(tools) }
+	if config, ok := GenerationConfigFromContext(ctx); ok {
+		applyGenerationConfig(model, config)
+	}
 
 	chatSession := model.StartChat()
 	if len(history) > 0 { chatSession.History = convertADKMessagesToGenaiContent(history) }
@@ -190,6 +264,7 @@ func (g *GeminiLLMProvider) GenerateContent(
 	iter := chatSession.SendMessageStream(ctx, latestPartsToSend...)
 	var aggregatedParts []genai.Part
 	var finalCandidate *genai.Candidate
+	var usage *genai.UsageMetadata
 
 	for {
 		resp, err := iter.Next()
@@ -200,7 +275,10 @@ func (g *GeminiLLMProvider) GenerateContent(
 			return nil, fmt.Errorf("failed during LLM stream: %w", err)
 		}
 
-		// The last response in the stream contains the final state (e.g., FinishReason).
+		// The last response in the stream contains the final state (e.g., FinishReason) and the token counts.
+		if resp.UsageMetadata != nil {
+			usage = resp.UsageMetadata
+		}
 		if len(resp.Candidates) > 0 {
 			finalCandidate = resp.Candidates[0]
 			if finalCandidate.Content != nil {
@@ -219,7 +297,36 @@ func (g *GeminiLLMProvider) GenerateContent(
 		Role:  "model",
 	}
 
-	return convertGenaiCandidateToADKMessage(finalCandidate), nil
+	adkMessage := convertGenaiCandidateToADKMessage(finalCandidate)
+	if usage != nil {
+		adkMessage.UsageMetadata = &modelstypes.UsageMetadata{
+			PromptTokenCount:     int(usage.PromptTokenCount),
+			CandidatesTokenCount: int(usage.CandidatesTokenCount),
+			TotalTokenCount:      int(usage.TotalTokenCount),
+		}
+	}
+	return adkMessage, nil
+}
+
+func applyGenerationConfig(model *genai.GenerativeModel, config *modelstypes.GenerationConfig) {
+	if config.Temperature != nil {
+		model.SetTemperature(*config.Temperature)
+	}
+	if config.TopP != nil {
+		model.SetTopP(*config.TopP)
+	}
+	if config.TopK != nil {
+		model.SetTopK(*config.TopK)
+	}
+	if config.MaxOutputTokens != nil {
+		model.SetMaxOutputTokens(*config.MaxOutputTokens)
+	}
+	if len(config.StopSequences) > 0 {
+		model.StopSequences = config.StopSequences
+	}
+	if config.ResponseMIMEType != "" {
+		model.ResponseMIMEType = config.ResponseMIMEType
+	}
 }
 
 func consolidateTextParts(parts []genai.Part) []genai.Part {
@@ -17,3 +17,17 @@ type LLMProvider interface {
 		latestMessage modelstypes.Message,
 	) (*modelstypes.Message, error)
 }
+
+type generationConfigKey struct{}
+
+// WithGenerationConfig attaches the generation config for the model calls
+// made with ctx. Providers that support a setting apply it and ignore the
+// rest.
+func WithGenerationConfig(ctx context.Context, config *modelstypes.GenerationConfig) context.Context {
+	return context.WithValue(ctx, generationConfigKey{}, config)
+}
+
+func GenerationConfigFromContext(ctx context.Context) (*modelstypes.GenerationConfig, bool) {
+	config, ok := ctx.Value(generationConfigKey{}).(*modelstypes.GenerationConfig)
+	return config, ok && config != nil
+}
@@ -0,0 +1,92 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/KennethanCeyer/adk-go/adk"
+	"github.com/KennethanCeyer/adk-go/agents/interfaces"
+	"github.com/KennethanCeyer/adk-go/agents/invocation"
+	modelstypes "github.com/KennethanCeyer/adk-go/models/types"
+	"github.com/KennethanCeyer/adk-go/sessions"
+	"github.com/KennethanCeyer/adk-go/tools"
+)
+
+// AgentTool exposes an agent as a tool. Each call runs one conversational turn
+// through the same session machinery as the CLI runner, so callers can keep a
+// conversation going by passing back the returned session_id.
+type AgentTool struct {
+	// RunConfig limits each turn; nil means no limits.
+	RunConfig *invocation.RunConfig
+
+	agent interfaces.Agent
+}
+
+func NewAgentTool(agent interfaces.Agent) tools.Tool {
+	return &AgentTool{agent: agent}
+}
+
+func (t *AgentTool) Name() string { return t.agent.GetName() }
+
+func (t *AgentTool) Description() string {
+	if desc := t.agent.GetDescription(); desc != "" {
+		return desc
+	}
+	return fmt.Sprintf("Sends a message to the '%s' agent and returns its reply.", t.agent.GetName())
+}
+
+func (t *AgentTool) Parameters() any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"message": map[string]any{
+				"type":        "string",
+				"description": "The message to send to the agent.",
+			},
+			"session_id": map[string]any{
+				"type":        "string",
+				"description": "Optional ID of a previous session to continue. A new session is started when omitted.",
+			},
+		},
+		"required": []string{"message"},
+	}
+}
+
+func (t *AgentTool) Execute(ctx context.Context, args any) (any, error) {
+	argsMap, ok := args.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s: invalid arguments format, expected map[string]any, got %T", t.Name(), args)
+	}
+	message, ok := argsMap["message"].(string)
+	if !ok || message == "" {
+		return nil, fmt.Errorf("%s: 'message' is a required argument and must be a non-empty string", t.Name())
+	}
+	sessionID, _ := argsMap["session_id"].(string)
+
+	var sess *sessions.Session
+	if sessionID != "" {
+		existing, err := sessions.Get(sessionID)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", t.Name(), err)
+		}
+		if existing.AgentName != t.agent.GetName() {
+			return nil, fmt.Errorf("%s: session '%s' belongs to agent '%s'", t.Name(), sessionID, existing.AgentName)
+		}
+		sess = existing
+	} else {
+		sess = sessions.GetOrCreate(t.agent.GetName(), "")
+	}
+
+	userMessage := modelstypes.Message{Role: "user", Parts: []modelstypes.Part{{Text: &message}}}
+	if t.RunConfig != nil {
+		ctx = invocation.WithRunConfig(ctx, t.RunConfig)
+	}
+	response, err := adk.RunTurn(ctx, t.agent, sess, userMessage)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"response":   adk.ResponseText(response),
+		"session_id": sess.ID,
+	}, nil
+}
@@ -0,0 +1,77 @@
+package mcp
+
+import (
+	"encoding/json"
+	"log"
+	"strings"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+// toJSONSchema converts a tool's Parameters() value into a JSON Schema object.
+// Tools in this repository declare their parameters either as plain maps or as
+// *genai.Schema values, so both are handled here.
+func toJSONSchema(params any) map[string]any {
+	switch p := params.(type) {
+	case nil:
+		return map[string]any{"type": "object"}
+	case map[string]any:
+		return p
+	case *genai.Schema:
+		return genaiSchemaToMap(p)
+	default:
+		raw, err := json.Marshal(p)
+		if err == nil {
+			var m map[string]any
+			if err := json.Unmarshal(raw, &m); err == nil {
+				return m
+			}
+		}
+		log.Printf("Warning: unhandled tool parameter schema type %T, exposing an empty object schema", params)
+		return map[string]any{"type": "object"}
+	}
+}
+
+func genaiSchemaToMap(s *genai.Schema) map[string]any {
+	if s == nil {
+		return nil
+	}
+	m := map[string]any{}
+	switch s.Type {
+	case genai.TypeObject:
+		m["type"] = "object"
+	case genai.TypeString:
+		m["type"] = "string"
+	case genai.TypeInteger:
+		m["type"] = "integer"
+	case genai.TypeNumber:
+		m["type"] = "number"
+	case genai.TypeBoolean:
+		m["type"] = "boolean"
+	case genai.TypeArray:
+		m["type"] = "array"
+	}
+	if s.Description != "" {
+		m["description"] = s.Description
+	}
+	if s.Format != "" && !strings.EqualFold(s.Format, "enum") {
+		m["format"] = s.Format
+	}
+	if len(s.Enum) > 0 {
+		m["enum"] = s.Enum
+	}
+	if s.Items != nil {
+		m["items"] = genaiSchemaToMap(s.Items)
+	}
+	if len(s.Properties) > 0 {
+		props := make(map[string]any, len(s.Properties))
+		for name, prop := range s.Properties {
+			props[name] = genaiSchemaToMap(prop)
+		}
+		m["properties"] = props
+	}
+	if len(s.Required) > 0 {
+		m["required"] = s.Required
+	}
+	return m
+}
@@ -0,0 +1,331 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+
+	"github.com/KennethanCeyer/adk-go/tools"
+)
+
+const maxMessageSize = 10 << 20 // 10 MiB
+
+// Server exposes a set of tools to MCP hosts over stdio or HTTP.
+type Server struct {
+	name    string
+	version string
+
+	// AllowedOrigins lists the browser origins (such as
+	// "https://app.example.com") that may call ServeHTTP in addition to
+	// loopback origins. Requests without an Origin header are always allowed.
+	AllowedOrigins []string
+
+	mu    sync.RWMutex
+	tools map[string]tools.Tool
+}
+
+// NewServer creates an MCP server that reports the given name and version
+// to connecting clients.
+func NewServer(name, version string) *Server {
+	return &Server{
+		name:    name,
+		version: version,
+		tools:   make(map[string]tools.Tool),
+	}
+}
+
+// AddTool registers a tool under its own name.
+func (s *Server) AddTool(t tools.Tool) error {
+	return s.AddToolAs(t.Name(), t)
+}
+
+// AddToolAs registers a tool under an explicit name, which lets the same
+// tool be exposed for several agents without colliding.
+func (s *Server) AddToolAs(name string, t tools.Tool) error {
+	if t == nil {
+		return fmt.Errorf("tool cannot be nil")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.tools[name]; exists {
+		return fmt.Errorf("tool '%s' is already registered", name)
+	}
+	s.tools[name] = t
+	return nil
+}
+
+// ToolNames returns the registered tool names in sorted order.
+func (s *Server) ToolNames() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	names := make([]string, 0, len(s.tools))
+	for name := range s.tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ServeStdio reads newline-delimited JSON-RPC messages from in and writes
+// responses to out until in is exhausted or ctx is cancelled.
+func (s *Server) ServeStdio(ctx context.Context, in io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMessageSize)
+
+	var writeMu sync.Mutex
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for scanner.Scan() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		msg := append([]byte(nil), line...)
+
+		// Requests are handled concurrently so that a long-running agent
+		// call does not block pings or other tool calls.
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reply := s.HandleMessage(ctx, msg)
+			if reply == nil {
+				return
+			}
+			writeMu.Lock()
+			defer writeMu.Unlock()
+			if _, err := out.Write(append(reply, '\n')); err != nil {
+				log.Printf("MCP: failed to write response: %v", err)
+			}
+		}()
+	}
+	return scanner.Err()
+}
+
+// ServeHTTP implements the request/response subset of the MCP streamable
+// HTTP transport: each POST carries one JSON-RPC message (or a batch) and
+// receives the JSON-encoded reply. Requests from browser origins other than
+// loopback and AllowedOrigins are rejected, so that web pages cannot drive the
+// server's tools.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if origin := r.Header.Get("Origin"); origin != "" && !s.originAllowed(origin) {
+		http.Error(w, "Origin not allowed", http.StatusForbidden)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxMessageSize))
+	if err != nil {
+		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+		return
+	}
+	reply := s.HandleMessage(r.Context(), body)
+	if reply == nil {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(reply)
+}
+
+func (s *Server) originAllowed(origin string) bool {
+	for _, allowed := range s.AllowedOrigins {
+		if origin == allowed {
+			return true
+		}
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	host := u.Hostname()
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// HandleMessage processes a single JSON-RPC message or batch and returns the
+// encoded response, or nil when the message only contained notifications.
+func (s *Server) HandleMessage(ctx context.Context, msg []byte) []byte {
+	msg = bytes.TrimSpace(msg)
+	if len(msg) > 0 && msg[0] == '[' {
+		var batch []json.RawMessage
+		if err := json.Unmarshal(msg, &batch); err != nil {
+			return encode(errorResponse(nil, codeParseError, "invalid JSON: "+err.Error()))
+		}
+		var replies []*response
+		for _, item := range batch {
+			if resp := s.handleOne(ctx, item); resp != nil {
+				replies = append(replies, resp)
+			}
+		}
+		if len(replies) == 0 {
+			return nil
+		}
+		return encode(replies)
+	}
+	resp := s.handleOne(ctx, msg)
+	if resp == nil {
+		return nil
+	}
+	return encode(resp)
+}
+
+func (s *Server) handleOne(ctx context.Context, raw []byte) *response {
+	var req request
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return errorResponse(nil, codeParseError, "invalid JSON: "+err.Error())
+	}
+	if req.JSONRPC != jsonRPCVersion || req.Method == "" {
+		if req.isNotification() {
+			return nil
+		}
+		return errorResponse(req.ID, codeInvalidRequest, "invalid JSON-RPC request")
+	}
+
+	result, err := s.dispatch(ctx, &req)
+	if req.isNotification() {
+		return nil
+	}
+	if err != nil {
+		if rpcErr, ok := err.(*rpcError); ok {
+			return errorResponse(req.ID, rpcErr.Code, rpcErr.Message)
+		}
+		return errorResponse(req.ID, codeInternalError, err.Error())
+	}
+	return &response{JSONRPC: jsonRPCVersion, ID: req.ID, Result: result}
+}
+
+func (s *Server) dispatch(ctx context.Context, req *request) (any, error) {
+	switch req.Method {
+	case "initialize":
+		return s.initialize(req.Params)
+	case "notifications/initialized", "notifications/cancelled":
+		return nil, nil
+	case "ping":
+		return struct{}{}, nil
+	case "tools/list":
+		return s.listTools(), nil
+	case "tools/call":
+		return s.callTool(ctx, req.Params)
+	default:
+		return nil, &rpcError{Code: codeMethodNotFound, Message: fmt.Sprintf("method '%s' not found", req.Method)}
+	}
+}
+
+func (s *Server) initialize(rawParams json.RawMessage) (any, error) {
+	var params initializeParams
+	if len(rawParams) > 0 {
+		if err := json.Unmarshal(rawParams, &params); err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: "invalid initialize params: " + err.Error()}
+		}
+	}
+	version := ProtocolVersion
+	for _, v := range supportedProtocolVersions {
+		if v == params.ProtocolVersion {
+			version = v
+		}
+	}
+	log.Printf("MCP: client '%s' (%s) initialized with protocol %s", params.ClientInfo.Name, params.ClientInfo.Version, version)
+	return initializeResult{
+		ProtocolVersion: version,
+		Capabilities:    map[string]any{"tools": map[string]any{"listChanged": false}},
+		ServerInfo:      implementation{Name: s.name, Version: s.version},
+	}, nil
+}
+
+func (s *Server) listTools() listToolsResult {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := listToolsResult{Tools: make([]toolDescriptor, 0, len(s.tools))}
+	for name, t := range s.tools {
+		result.Tools = append(result.Tools, toolDescriptor{
+			Name:        name,
+			Description: t.Description(),
+			InputSchema: toJSONSchema(t.Parameters()),
+		})
+	}
+	sort.Slice(result.Tools, func(i, j int) bool {
+		return result.Tools[i].Name < result.Tools[j].Name
+	})
+	return result
+}
+
+func (s *Server) callTool(ctx context.Context, rawParams json.RawMessage) (any, error) {
+	var params callToolParams
+	if err := json.Unmarshal(rawParams, &params); err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: "invalid tools/call params: " + err.Error()}
+	}
+	s.mu.RLock()
+	t, found := s.tools[params.Name]
+	s.mu.RUnlock()
+	if !found {
+		return nil, &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("tool '%s' not found", params.Name)}
+	}
+	if params.Arguments == nil {
+		params.Arguments = map[string]any{}
+	}
+
+	// MCP clients have no way to answer a confirmation request, so calls that
+	// need one are refused rather than run unconfirmed.
+	if ct, ok := t.(tools.ConfirmableTool); ok && ct.RequiresConfirmation(params.Arguments) {
+		return callToolResult{
+			Content: []content{{Type: "text", Text: fmt.Sprintf("tool '%s' requires user confirmation, which is not available over MCP", params.Name)}},
+			IsError: true,
+		}, nil
+	}
+
+	log.Printf("MCP: calling tool '%s'", params.Name)
+	toolResult, err := t.Execute(ctx, params.Arguments)
+	if err != nil {
+		// Tool failures are reported in-band so the calling model can react to them.
+		return callToolResult{
+			Content: []content{{Type: "text", Text: fmt.Sprintf("tool '%s' execution failed: %v", params.Name, err)}},
+			IsError: true,
+		}, nil
+	}
+
+	resultBytes, err := json.Marshal(toolResult)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode result of tool '%s': %w", params.Name, err)
+	}
+	result := callToolResult{Content: []content{{Type: "text", Text: string(resultBytes)}}}
+	// Structured content must be a JSON object, so other results use the {"result": ...} envelope.
+	if m, err := tools.NormalizeResult(toolResult); err == nil {
+		result.StructuredContent = m
+	}
+	return result, nil
+}
+
+func errorResponse(id json.RawMessage, code int, message string) *response {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	return &response{JSONRPC: jsonRPCVersion, ID: id, Error: &rpcError{Code: code, Message: message}}
+}
+
+func encode(v any) []byte {
+	b, err := json.Marshal(v)
+	if err != nil {
+		log.Printf("MCP: failed to encode response: %v", err)
+		return []byte(`{"jsonrpc":"2.0","id":null,"error":{"code":-32603,"message":"failed to encode response"}}`)
+	}
+	return b
+}
@@ -0,0 +1,92 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/KennethanCeyer/adk-go/tools"
+)
+
+type recordingTool struct {
+	calls int
+}
+
+func (t *recordingTool) Name() string        { return "record" }
+func (t *recordingTool) Description() string { return "Records calls." }
+func (t *recordingTool) Parameters() any     { return map[string]any{"type": "object"} }
+func (t *recordingTool) Execute(ctx context.Context, args any) (any, error) {
+	t.calls++
+	return "ok", nil
+}
+
+const pingMessage = `{"jsonrpc":"2.0","id":1,"method":"ping"}`
+
+func TestServeHTTPOrigin(t *testing.T) {
+	server := NewServer("test", "1.0")
+	server.AllowedOrigins = []string{"https://app.example.com"}
+
+	tests := []struct {
+		name   string
+		origin string
+		status int
+	}{
+		{"no origin", "", http.StatusOK},
+		{"localhost", "http://localhost:3000", http.StatusOK},
+		{"loopback ipv4", "http://127.0.0.1:8081", http.StatusOK},
+		{"loopback ipv6", "http://[::1]:8081", http.StatusOK},
+		{"allowed origin", "https://app.example.com", http.StatusOK},
+		{"foreign origin", "https://evil.example.com", http.StatusForbidden},
+		{"localhost lookalike", "http://localhost.evil.example.com", http.StatusForbidden},
+		{"null origin", "null", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(pingMessage))
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			rec := httptest.NewRecorder()
+			server.ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+		})
+	}
+}
+
+func TestCallToolRefusesConfirmation(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  tools.ConfirmationPolicy
+		refused bool
+	}{
+		{"always", tools.ConfirmAlways, true},
+		{"never", func(map[string]any) bool { return false }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := &recordingTool{}
+			server := NewServer("test", "1.0")
+			if err := server.AddTool(tools.WithConfirmation(inner, tt.policy)); err != nil {
+				t.Fatal(err)
+			}
+			reply := server.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"record","arguments":{}}}`))
+			var resp struct {
+				Result callToolResult `json:"result"`
+			}
+			if err := json.Unmarshal(reply, &resp); err != nil {
+				t.Fatalf("decoding %s: %v", reply, err)
+			}
+			if resp.Result.IsError != tt.refused {
+				t.Errorf("isError = %v, want %v: %s", resp.Result.IsError, tt.refused, reply)
+			}
+			if ran := inner.calls > 0; ran == tt.refused {
+				t.Errorf("tool ran = %v, want %v", ran, !tt.refused)
+			}
+		})
+	}
+}
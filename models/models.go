@@ -11,6 +11,7 @@ type LlmRequest struct {
 	Tools             []tools.Tool
 	History           []modelstypes.Message
 	LatestMessage     modelstypes.Message
+	GenerationConfig  *modelstypes.GenerationConfig
 }
 
 type LlmResponse struct {
@@ -3,12 +3,23 @@ package types
 type Message struct {
 	Role  string `json:"role"`
 	Parts []Part `json:"parts"`
+	// UsageMetadata is set by model providers on the messages they return.
+	UsageMetadata *UsageMetadata `json:"usageMetadata,omitempty"`
+}
+
+// UsageMetadata reports the tokens a model call used.
+type UsageMetadata struct {
+	PromptTokenCount     int `json:"promptTokenCount"`
+	CandidatesTokenCount int `json:"candidatesTokenCount"`
+	TotalTokenCount      int `json:"totalTokenCount"`
 }
 
 type Part struct {
-	Text             *string           `json:"text,omitempty"`
-	FunctionCall     *FunctionCall     `json:"functionCall,omitempty"`
-	FunctionResponse *FunctionResponse `json:"functionResponse,omitempty"`
+	Text                *string              `json:"text,omitempty"`
+	FunctionCall        *FunctionCall        `json:"functionCall,omitempty"`
+	FunctionResponse    *FunctionResponse    `json:"functionResponse,omitempty"`
+	ExecutableCode      *ExecutableCode      `json:"executableCode,omitempty"`
+	CodeExecutionResult *CodeExecutionResult `json:"codeExecutionResult,omitempty"`
 }
 
 type FunctionCall struct {
@@ -20,3 +31,34 @@ type FunctionResponse struct {
 	Name     string `json:"name"`
 	Response any    `json:"response"` // Can be any serializable type
 }
+
+// ExecutableCode is a block of code produced by the model that is meant to be
+// executed, e.g. by a code executor configured on the agent.
+type ExecutableCode struct {
+	Language string `json:"language"` // Lowercase language name, e.g. "python" or "go"
+	Code     string `json:"code"`
+}
+
+// Outcomes of a code execution.
+const (
+	OutcomeOK               = "OUTCOME_OK"
+	OutcomeFailed           = "OUTCOME_FAILED"
+	OutcomeDeadlineExceeded = "OUTCOME_DEADLINE_EXCEEDED"
+)
+
+// CodeExecutionResult is the result of running an ExecutableCode block.
+type CodeExecutionResult struct {
+	Outcome string `json:"outcome"`
+	Output  string `json:"output"` // stdout on success, stderr or a description of the failure otherwise
+}
+
+// GenerationConfig tunes how the model generates a response. Nil fields use
+// the model's defaults.
+type GenerationConfig struct {
+	Temperature      *float32 `json:"temperature,omitempty"`
+	TopP             *float32 `json:"topP,omitempty"`
+	TopK             *int32   `json:"topK,omitempty"`
+	MaxOutputTokens  *int32   `json:"maxOutputTokens,omitempty"`
+	StopSequences    []string `json:"stopSequences,omitempty"`
+	ResponseMIMEType string   `json:"responseMimeType,omitempty"` // e.g. "application/json"
+}
@@ -0,0 +1,45 @@
+package sessions
+
+import (
+	"sort"
+	"time"
+)
+
+// Artifact is a named blob stored alongside a session, such as a tool result
+// that was too large to hand to the model directly.
+type Artifact struct {
+	Name      string
+	MIMEType  string
+	Data      []byte
+	CreatedAt time.Time
+}
+
+// SaveArtifact stores data under name, replacing any artifact with the same name.
+func (s *Session) SaveArtifact(name, mimeType string, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.artifacts == nil {
+		s.artifacts = make(map[string]*Artifact)
+	}
+	s.artifacts[name] = &Artifact{Name: name, MIMEType: mimeType, Data: data, CreatedAt: time.Now()}
+}
+
+// LoadArtifact returns the artifact stored under name.
+func (s *Session) LoadArtifact(name string) (*Artifact, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	artifact, ok := s.artifacts[name]
+	return artifact, ok
+}
+
+// ListArtifacts returns the names of the session's artifacts in sorted order.
+func (s *Session) ListArtifacts() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := make([]string, 0, len(s.artifacts))
+	for name := range s.artifacts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
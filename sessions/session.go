@@ -1,6 +1,7 @@
 package sessions
 
 import (
+	"sync"
 	"time"
 
 	modelstypes "github.com/KennethanCeyer/adk-go/models/types"
@@ -12,12 +13,59 @@ type Session struct {
 	State          map[string]any
 	History        []modelstypes.Message
 	LastUpdateTime time.Time
+	// ActiveAgent names the agent that answers the next message after a
+	// transfer. Empty means the session's root agent.
+	ActiveAgent string
+
+	mu        sync.Mutex // Protects artifacts and State, which agents and tools may write concurrently
+	artifacts map[string]*Artifact
+	turnMu    sync.Mutex // Held for the duration of a turn, see BeginTurn
+}
+
+// BeginTurn waits until no other turn is running on the session and returns
+// the function that ends the turn. Runners hold it while they run the agent
+// and update History and ActiveAgent, so that concurrent requests for the
+// same session take turns instead of racing.
+func (s *Session) BeginTurn() (end func()) {
+	s.turnMu.Lock()
+	return s.turnMu.Unlock
 }
 
 func (s *Session) AddMessage(msg modelstypes.Message) {
 	s.History = append(s.History, msg)
 }
 
+// SetState stores value under key in the session state. Agents running
+// concurrently must use it instead of writing to State directly.
+func (s *Session) SetState(key string, value any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.State == nil {
+		s.State = make(map[string]any)
+	}
+	s.State[key] = value
+}
+
+// GetState returns the value stored under key in the session state.
+func (s *Session) GetState(key string) (any, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, ok := s.State[key]
+	return value, ok
+}
+
+// CopyState returns a shallow copy of the session state that is safe to read
+// while other agents update the session.
+func (s *Session) CopyState() map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state := make(map[string]any, len(s.State))
+	for k, v := range s.State {
+		state[k] = v
+	}
+	return state
+}
+
 func (s *Session) PruneHistory(maxTurns int) {
 	// Implementation for history pruning can be added here.
 }
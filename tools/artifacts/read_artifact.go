@@ -0,0 +1,142 @@
+package artifacts
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/KennethanCeyer/adk-go/agents/invocation"
+)
+
+const defaultPageBytes = 16 << 10 // 16 KiB
+
+// ReadArtifactTool lets the model page through an artifact stored in the
+// current session, such as a tool result that was offloaded because it was
+// too large.
+type ReadArtifactTool struct {
+	pageBytes int
+}
+
+// NewReadArtifactTool creates the tool. pageBytes caps how much is returned
+// per call; zero or less uses 16 KiB.
+func NewReadArtifactTool(pageBytes int) *ReadArtifactTool {
+	if pageBytes <= 0 {
+		pageBytes = defaultPageBytes
+	}
+	return &ReadArtifactTool{pageBytes: pageBytes}
+}
+
+func (t *ReadArtifactTool) Name() string { return "read_artifact" }
+
+func (t *ReadArtifactTool) Description() string {
+	return fmt.Sprintf("Reads a page of a stored artifact, such as a tool result that was too large to return in full. Returns at most %d bytes per call; use next_offset to continue reading.", t.pageBytes)
+}
+
+func (t *ReadArtifactTool) Parameters() any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"name": map[string]any{
+				"type":        "string",
+				"description": "The artifact name, as given in the truncated tool result.",
+			},
+			"offset": map[string]any{
+				"type":        "integer",
+				"description": "Byte offset to start reading from. Defaults to 0.",
+			},
+			"length": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("Maximum number of bytes to read. Defaults to and is capped at %d.", t.pageBytes),
+			},
+		},
+		"required": []string{"name"},
+	}
+}
+
+func (t *ReadArtifactTool) Execute(ctx context.Context, args any) (any, error) {
+	argsMap, ok := args.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("read_artifact: invalid arguments format, expected map[string]any, got %T", args)
+	}
+	name, ok := argsMap["name"].(string)
+	if !ok || name == "" {
+		return nil, fmt.Errorf("read_artifact: 'name' is a required argument and must be a string")
+	}
+	offset, err := intArg(argsMap, "offset")
+	if err != nil {
+		return nil, err
+	}
+	length, err := intArg(argsMap, "length")
+	if err != nil {
+		return nil, err
+	}
+	if offset < 0 {
+		return nil, fmt.Errorf("read_artifact: 'offset' cannot be negative")
+	}
+	if length <= 0 || length > t.pageBytes {
+		length = t.pageBytes
+	}
+
+	sess := invocation.FromContext(ctx).Session
+	if sess == nil {
+		return nil, fmt.Errorf("read_artifact: no session is available")
+	}
+	artifact, found := sess.LoadArtifact(name)
+	if !found {
+		available := sess.ListArtifacts()
+		if len(available) == 0 {
+			return nil, fmt.Errorf("read_artifact: artifact '%s' not found; the session has no artifacts", name)
+		}
+		return nil, fmt.Errorf("read_artifact: artifact '%s' not found; available artifacts: %s", name, strings.Join(available, ", "))
+	}
+
+	data := artifact.Data
+	if offset > len(data) {
+		return nil, fmt.Errorf("read_artifact: offset %d is beyond the end of the artifact (%d bytes)", offset, len(data))
+	}
+	start := runeStart(data, offset)
+	end := start + length
+	if end >= len(data) {
+		end = len(data)
+	} else {
+		end = runeStart(data, end)
+		if end == start {
+			// A page must make progress even if it is smaller than one rune.
+			_, size := utf8.DecodeRune(data[start:])
+			end = start + size
+		}
+	}
+
+	return map[string]any{
+		"name":        name,
+		"content":     string(data[start:end]),
+		"offset":      start,
+		"next_offset": end,
+		"total_bytes": len(data),
+		"eof":         end >= len(data),
+	}, nil
+}
+
+// runeStart moves offset back to the start of the UTF-8 sequence it points into.
+func runeStart(data []byte, offset int) int {
+	for offset > 0 && offset < len(data) && !utf8.RuneStart(data[offset]) {
+		offset--
+	}
+	return offset
+}
+
+func intArg(args map[string]any, key string) (int, error) {
+	switch v := args[key].(type) {
+	case nil:
+		return 0, nil
+	case float64:
+		return int(v), nil
+	case int:
+		return v, nil
+	case int64:
+		return int(v), nil
+	default:
+		return 0, fmt.Errorf("read_artifact: '%s' must be an integer, got %T", key, v)
+	}
+}
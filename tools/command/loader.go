@@ -0,0 +1,141 @@
+package command
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/KennethanCeyer/adk-go/tools"
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats for a command's stdout.
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+)
+
+var nameRe = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]*$`)
+
+// File is the top-level structure of a YAML tool definition file:
+//
+//	tools:
+//	  - name: disk_usage
+//	    description: Reports the disk usage of a directory.
+//	    parameters:
+//	      type: object
+//	      properties:
+//	        path: {type: string, description: Directory to measure.}
+//	      required: [path]
+//	    command: [du, -sh, "--", "{path}"]
+//	    timeout: 10s
+//	    output: text
+type File struct {
+	Tools []Definition `yaml:"tools"`
+}
+
+// Definition describes a single command-line tool.
+type Definition struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	// Parameters is the JSON schema of the tool's arguments.
+	Parameters map[string]any `yaml:"parameters"`
+	// Command is the argv template. "{arg}" is replaced by the value of an
+	// argument and "{{" and "}}" produce literal braces. Elements that
+	// reference an argument the model omitted are dropped, which makes
+	// optional flags such as "--limit={limit}" possible. An element that is
+	// exactly "{arg}" expands to one element per item of an array argument.
+	// The command is run directly, without a shell.
+	Command []string `yaml:"command"`
+	// WorkDir defaults to the directory containing the definition file.
+	WorkDir string `yaml:"workdir"`
+	// Timeout is a Go duration such as "30s". Defaults to 30s.
+	Timeout string `yaml:"timeout"`
+	// Output is "text" (the default) or "json".
+	Output string `yaml:"output"`
+	// Env holds extra KEY=VALUE entries for the otherwise scrubbed environment.
+	Env []string `yaml:"env"`
+	// MaxOutputBytes caps the captured stdout and stderr each.
+	MaxOutputBytes int `yaml:"max_output_bytes"`
+}
+
+// LoadFile reads tool definitions from a YAML file. Relative working
+// directories and commands are resolved against the file's directory.
+func LoadFile(path string) ([]*Tool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("command: failed to read '%s': %w", path, err)
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("command: invalid path '%s': %w", path, err)
+	}
+	loaded, err := Parse(data, filepath.Dir(absPath))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return loaded, nil
+}
+
+// Parse builds tools from YAML definitions, resolving relative paths
+// against baseDir.
+func Parse(data []byte, baseDir string) ([]*Tool, error) {
+	var file File
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("command: invalid YAML: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	var loaded []*Tool
+	for i, def := range file.Tools {
+		tool, err := newTool(def, baseDir)
+		if err != nil {
+			if def.Name != "" {
+				return nil, fmt.Errorf("command: tool '%s': %w", def.Name, err)
+			}
+			return nil, fmt.Errorf("command: tool #%d: %w", i+1, err)
+		}
+		if seen[tool.Name()] {
+			return nil, fmt.Errorf("command: duplicate tool name '%s'", tool.Name())
+		}
+		seen[tool.Name()] = true
+		loaded = append(loaded, tool)
+	}
+	return loaded, nil
+}
+
+// RegisterFile loads the tools defined in path and adds them to the tool
+// registry, so that they can be referenced by name.
+func RegisterFile(path string) ([]*Tool, error) {
+	loaded, err := LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, tool := range loaded {
+		if err := tools.RegisterTool(tool); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return loaded, nil
+}
+
+func parseTimeout(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timeout '%s': %w", s, err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("timeout must be positive, got '%s'", s)
+	}
+	return d, nil
+}
@@ -0,0 +1,253 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/KennethanCeyer/adk-go/tools/shell"
+)
+
+var placeholderRe = regexp.MustCompile(`\{\{|\}\}|\{([a-zA-Z_][a-zA-Z0-9_]*)\}`)
+
+// Tool runs a command built from a Definition. Execution goes through the
+// shell package, so the command runs without a shell, in a scrubbed
+// environment, with a timeout and an output cap. Only the binary named in the
+// definition is allowed to run.
+type Tool struct {
+	name        string
+	description string
+	parameters  map[string]any
+	required    []string
+	command     []string
+	output      string
+	runner      *shell.Tool
+}
+
+func newTool(def Definition, baseDir string) (*Tool, error) {
+	if !nameRe.MatchString(def.Name) {
+		return nil, fmt.Errorf("invalid name '%s': use letters, digits, '_' and '-'", def.Name)
+	}
+	if strings.TrimSpace(def.Description) == "" {
+		return nil, fmt.Errorf("description is required")
+	}
+	if len(def.Command) == 0 || def.Command[0] == "" {
+		return nil, fmt.Errorf("command is required")
+	}
+	if placeholderRe.MatchString(def.Command[0]) {
+		return nil, fmt.Errorf("the executable '%s' cannot contain placeholders", def.Command[0])
+	}
+
+	parameters := def.Parameters
+	if parameters == nil {
+		parameters = map[string]any{"type": "object", "properties": map[string]any{}}
+	}
+	properties, _ := parameters["properties"].(map[string]any)
+	for _, element := range def.Command[1:] {
+		for _, m := range placeholderRe.FindAllStringSubmatch(element, -1) {
+			if m[1] == "" {
+				continue
+			}
+			if _, declared := properties[m[1]]; !declared {
+				return nil, fmt.Errorf("command references '{%s}', which is not declared in parameters.properties", m[1])
+			}
+		}
+	}
+	var required []string
+	if list, ok := parameters["required"].([]any); ok {
+		for _, r := range list {
+			if s, ok := r.(string); ok {
+				required = append(required, s)
+			}
+		}
+	}
+
+	output := def.Output
+	if output == "" {
+		output = OutputText
+	}
+	if output != OutputText && output != OutputJSON {
+		return nil, fmt.Errorf("output must be '%s' or '%s', got '%s'", OutputText, OutputJSON, def.Output)
+	}
+	timeout, err := parseTimeout(def.Timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	workDir := def.WorkDir
+	if workDir == "" {
+		workDir = baseDir
+	} else if !filepath.IsAbs(workDir) {
+		workDir = filepath.Join(baseDir, workDir)
+	}
+	command := append([]string{}, def.Command...)
+	if strings.ContainsRune(command[0], filepath.Separator) && !filepath.IsAbs(command[0]) {
+		command[0] = filepath.Join(workDir, command[0])
+	}
+
+	runner, err := shell.NewTool(shell.Config{
+		WorkDir:        workDir,
+		Allow:          []shell.Rule{{Binary: command[0]}},
+		Timeout:        timeout,
+		MaxOutputBytes: def.MaxOutputBytes,
+		Env:            def.Env,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Tool{
+		name:        def.Name,
+		description: def.Description,
+		parameters:  parameters,
+		required:    required,
+		command:     command,
+		output:      output,
+		runner:      runner,
+	}, nil
+}
+
+func (t *Tool) Name() string { return t.name }
+
+func (t *Tool) Description() string { return t.description }
+
+func (t *Tool) Parameters() any { return t.parameters }
+
+func (t *Tool) Execute(ctx context.Context, args any) (any, error) {
+	argsMap, ok := args.(map[string]any)
+	if !ok {
+		if args != nil {
+			return nil, fmt.Errorf("%s: invalid arguments format, expected map[string]any, got %T", t.name, args)
+		}
+		argsMap = map[string]any{}
+	}
+	for _, name := range t.required {
+		if v, present := argsMap[name]; !present || v == nil {
+			return nil, fmt.Errorf("%s: '%s' is a required argument", t.name, name)
+		}
+	}
+	argv, err := t.expand(argsMap)
+	if err != nil {
+		return nil, err
+	}
+
+	cmdArgs := make([]any, len(argv)-1)
+	for i, a := range argv[1:] {
+		cmdArgs[i] = a
+	}
+	raw, err := t.runner.Execute(ctx, map[string]any{"command": argv[0], "args": cmdArgs})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", t.name, err)
+	}
+	res := raw.(map[string]any)
+	stdout, _ := res["stdout"].(string)
+	stderr, _ := res["stderr"].(string)
+	truncated, _ := res["truncated"].(bool)
+
+	if timedOut, _ := res["timed_out"].(bool); timedOut {
+		return nil, fmt.Errorf("%s: command timed out", t.name)
+	}
+	if exitCode, _ := res["exit_code"].(int); exitCode != 0 {
+		detail := strings.TrimSpace(stderr)
+		if detail == "" {
+			detail = strings.TrimSpace(stdout)
+		}
+		return nil, fmt.Errorf("%s: command exited with code %d: %s", t.name, exitCode, detail)
+	}
+
+	if t.output == OutputJSON {
+		if truncated {
+			return nil, fmt.Errorf("%s: JSON output exceeded the output limit", t.name)
+		}
+		var decoded any
+		if err := json.Unmarshal([]byte(stdout), &decoded); err != nil {
+			return nil, fmt.Errorf("%s: command output is not valid JSON: %w", t.name, err)
+		}
+		return decoded, nil
+	}
+
+	result := map[string]any{"output": stdout}
+	if stderr != "" {
+		result["stderr"] = stderr
+	}
+	if truncated {
+		result["truncated"] = true
+	}
+	return result, nil
+}
+
+// expand substitutes the arguments into the command template.
+func (t *Tool) expand(args map[string]any) ([]string, error) {
+	argv := []string{t.command[0]}
+	for _, element := range t.command[1:] {
+		// A lone placeholder for an array argument expands to several elements.
+		if m := placeholderRe.FindStringSubmatch(element); m != nil && m[0] == element && m[1] != "" {
+			if list, ok := args[m[1]].([]any); ok {
+				for _, item := range list {
+					s, err := t.format(m[1], item)
+					if err != nil {
+						return nil, err
+					}
+					argv = append(argv, s)
+				}
+				continue
+			}
+		}
+
+		missing := false
+		var formatErr error
+		expanded := placeholderRe.ReplaceAllStringFunc(element, func(match string) string {
+			switch match {
+			case "{{":
+				return "{"
+			case "}}":
+				return "}"
+			}
+			name := match[1 : len(match)-1]
+			value, present := args[name]
+			if !present || value == nil {
+				missing = true
+				return ""
+			}
+			s, err := t.format(name, value)
+			if err != nil && formatErr == nil {
+				formatErr = err
+			}
+			return s
+		})
+		if formatErr != nil {
+			return nil, formatErr
+		}
+		if !missing {
+			argv = append(argv, expanded)
+		}
+	}
+	return argv, nil
+}
+
+func (t *Tool) format(name string, value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case []any, map[string]any:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", fmt.Errorf("%s: cannot encode argument '%s': %w", t.name, name, err)
+		}
+		return string(data), nil
+	default:
+		return "", fmt.Errorf("%s: unsupported type %T for argument '%s'", t.name, value, name)
+	}
+}
@@ -0,0 +1,37 @@
+package tools
+
+// ConfirmationPolicy reports whether a call with the given arguments needs a
+// human to approve it before the tool runs.
+type ConfirmationPolicy func(args map[string]any) bool
+
+// ConfirmAlways requires confirmation for every call.
+func ConfirmAlways(map[string]any) bool { return true }
+
+// ConfirmNever runs every call without asking.
+func ConfirmNever(map[string]any) bool { return false }
+
+// ConfirmableTool is implemented by tools that may require confirmation.
+// Agents ask for confirmation before executing a call for which
+// RequiresConfirmation returns true.
+type ConfirmableTool interface {
+	Tool
+	RequiresConfirmation(args map[string]any) bool
+}
+
+// WithConfirmation wraps tool so that calls matching policy require
+// confirmation. A nil policy is treated as ConfirmAlways.
+func WithConfirmation(tool Tool, policy ConfirmationPolicy) Tool {
+	if policy == nil {
+		policy = ConfirmAlways
+	}
+	return &confirmedTool{Tool: tool, policy: policy}
+}
+
+type confirmedTool struct {
+	Tool
+	policy ConfirmationPolicy
+}
+
+func (t *confirmedTool) RequiresConfirmation(args map[string]any) bool {
+	return t.policy(args)
+}
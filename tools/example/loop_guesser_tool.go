@@ -6,6 +6,7 @@ import (
 	"math/rand"
 	"time"
 
+	"github.com/KennethanCeyer/adk-go/agents/invocation"
 	"github.com/google/generative-ai-go/genai"
 )
 
@@ -56,5 +57,9 @@ func (t *NumberGuesserTool) Execute(ctx context.Context, args any) (any, error)
 	if guess < t.secret { status = "too_low"
 	} else if guess > t.secret { status = "too_high"
 	} else { status = "correct" }
+	if status == "correct" {
+		// The game is over, so there is no point in another loop iteration.
+		invocation.FromContext(ctx).Escalate(fmt.Sprintf("guessed the secret number %d", guess))
+	}
 	return map[string]any{"status": status}, nil
 }
@@ -0,0 +1,426 @@
+package fs
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	iofs "io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+type ListDirTool struct{ ts *Toolset }
+
+func (t *ListDirTool) Name() string { return "list_dir" }
+
+func (t *ListDirTool) Description() string {
+	return "Lists the entries of a directory in the workspace."
+}
+
+func (t *ListDirTool) Parameters() any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"path": map[string]any{
+				"type":        "string",
+				"description": "Directory path relative to the workspace root. Defaults to the root.",
+			},
+		},
+	}
+}
+
+func (t *ListDirTool) Execute(ctx context.Context, args any) (any, error) {
+	argMap, err := argsMap(t.Name(), args)
+	if err != nil {
+		return nil, err
+	}
+	dirArg, err := stringArg(t.Name(), argMap, "path", false)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := t.ts.resolve(dirArg)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", t.Name(), err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to list '%s': %w", t.Name(), dirArg, err)
+	}
+
+	listed := make([]any, 0, len(entries))
+	for i, entry := range entries {
+		if i >= maxListEntries {
+			break
+		}
+		item := map[string]any{"name": entry.Name(), "type": entryType(entry.Type())}
+		if entry.Type().IsRegular() {
+			if info, err := entry.Info(); err == nil {
+				item["size"] = info.Size()
+			}
+		}
+		listed = append(listed, item)
+	}
+	return map[string]any{
+		"path":      t.ts.relative(dir),
+		"entries":   listed,
+		"truncated": len(entries) > maxListEntries,
+	}, nil
+}
+
+func entryType(mode iofs.FileMode) string {
+	switch {
+	case mode.IsDir():
+		return "dir"
+	case mode&iofs.ModeSymlink != 0:
+		return "symlink"
+	case mode.IsRegular():
+		return "file"
+	default:
+		return "other"
+	}
+}
+
+type GlobTool struct{ ts *Toolset }
+
+func (t *GlobTool) Name() string { return "glob" }
+
+func (t *GlobTool) Description() string {
+	return "Finds files in the workspace whose path matches a glob pattern. '**' matches any number of directories, e.g. '**/*.go'."
+}
+
+func (t *GlobTool) Parameters() any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"pattern": map[string]any{
+				"type":        "string",
+				"description": "Glob pattern relative to the workspace root, e.g. 'docs/*.md' or '**/*_test.go'.",
+			},
+		},
+		"required": []string{"pattern"},
+	}
+}
+
+func (t *GlobTool) Execute(ctx context.Context, args any) (any, error) {
+	argMap, err := argsMap(t.Name(), args)
+	if err != nil {
+		return nil, err
+	}
+	pattern, err := stringArg(t.Name(), argMap, "pattern", true)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := path.Match(strings.ReplaceAll(pattern, "**", "*"), ""); err != nil {
+		return nil, fmt.Errorf("%s: invalid pattern '%s': %w", t.Name(), pattern, err)
+	}
+	patternParts := strings.Split(path.Clean(pattern), "/")
+
+	var matches []any
+	truncated := false
+	err = filepath.WalkDir(t.ts.root, func(p string, d iofs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if p == t.ts.root {
+			return nil
+		}
+		rel := t.ts.relative(p)
+		if matchSegments(patternParts, strings.Split(rel, "/")) {
+			if len(matches) >= maxListEntries {
+				truncated = true
+				return iofs.SkipAll
+			}
+			matches = append(matches, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", t.Name(), err)
+	}
+	return map[string]any{"matches": matches, "truncated": truncated}, nil
+}
+
+// matchSegments matches path segments against pattern segments, where a
+// "**" segment matches zero or more path segments.
+func matchSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	ok, err := path.Match(pattern[0], segments[0])
+	if err != nil || !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], segments[1:])
+}
+
+type GrepTool struct{ ts *Toolset }
+
+func (t *GrepTool) Name() string { return "grep" }
+
+func (t *GrepTool) Description() string {
+	return "Searches file contents in the workspace for a regular expression and returns the matching lines."
+}
+
+func (t *GrepTool) Parameters() any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"pattern": map[string]any{
+				"type":        "string",
+				"description": "Regular expression (RE2 syntax) to search for.",
+			},
+			"path": map[string]any{
+				"type":        "string",
+				"description": "File or directory to search, relative to the workspace root. Defaults to the root.",
+			},
+			"include": map[string]any{
+				"type":        "string",
+				"description": "Optional glob applied to file names, e.g. '*.go'.",
+			},
+		},
+		"required": []string{"pattern"},
+	}
+}
+
+func (t *GrepTool) Execute(ctx context.Context, args any) (any, error) {
+	argMap, err := argsMap(t.Name(), args)
+	if err != nil {
+		return nil, err
+	}
+	pattern, err := stringArg(t.Name(), argMap, "pattern", true)
+	if err != nil {
+		return nil, err
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid pattern: %w", t.Name(), err)
+	}
+	searchArg, err := stringArg(t.Name(), argMap, "path", false)
+	if err != nil {
+		return nil, err
+	}
+	include, err := stringArg(t.Name(), argMap, "include", false)
+	if err != nil {
+		return nil, err
+	}
+	searchRoot, err := t.ts.resolve(searchArg)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", t.Name(), err)
+	}
+
+	var matches []any
+	truncated := false
+	err = filepath.WalkDir(searchRoot, func(p string, d iofs.DirEntry, walkErr error) error {
+		if walkErr != nil || d.IsDir() || !d.Type().IsRegular() {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if include != "" {
+			if ok, _ := path.Match(include, d.Name()); !ok {
+				return nil
+			}
+		}
+		fileMatches, err := t.grepFile(p, re, maxGrepMatches-len(matches))
+		if err != nil {
+			return nil
+		}
+		matches = append(matches, fileMatches...)
+		if len(matches) >= maxGrepMatches {
+			truncated = true
+			return iofs.SkipAll
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", t.Name(), err)
+	}
+	return map[string]any{"matches": matches, "truncated": truncated}, nil
+}
+
+func (t *GrepTool) grepFile(p string, re *regexp.Regexp, limit int) ([]any, error) {
+	info, err := os.Stat(p)
+	if err != nil || info.Size() > t.ts.maxReadBytes {
+		return nil, err
+	}
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.IndexByte(data, 0) >= 0 {
+		return nil, nil // Skip binary files.
+	}
+
+	var matches []any
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), int(t.ts.maxReadBytes)+1)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		if len(matches) >= limit {
+			break
+		}
+		line := scanner.Text()
+		if re.MatchString(line) {
+			matches = append(matches, map[string]any{
+				"path": t.ts.relative(p),
+				"line": lineNo,
+				"text": line,
+			})
+		}
+	}
+	return matches, nil
+}
+
+type ReadFileTool struct{ ts *Toolset }
+
+func (t *ReadFileTool) Name() string { return "read_file" }
+
+func (t *ReadFileTool) Description() string {
+	return "Reads a file in the workspace, optionally restricted to a range of lines."
+}
+
+func (t *ReadFileTool) Parameters() any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"path": map[string]any{
+				"type":        "string",
+				"description": "File path relative to the workspace root.",
+			},
+			"start_line": map[string]any{
+				"type":        "integer",
+				"description": "First line to return (1-based, inclusive). Defaults to the first line.",
+			},
+			"end_line": map[string]any{
+				"type":        "integer",
+				"description": "Last line to return (1-based, inclusive). Defaults to the last line.",
+			},
+		},
+		"required": []string{"path"},
+	}
+}
+
+func (t *ReadFileTool) Execute(ctx context.Context, args any) (any, error) {
+	argMap, err := argsMap(t.Name(), args)
+	if err != nil {
+		return nil, err
+	}
+	fileArg, err := stringArg(t.Name(), argMap, "path", true)
+	if err != nil {
+		return nil, err
+	}
+	startLine, err := intArg(t.Name(), argMap, "start_line")
+	if err != nil {
+		return nil, err
+	}
+	endLine, err := intArg(t.Name(), argMap, "end_line")
+	if err != nil {
+		return nil, err
+	}
+	if startLine < 1 {
+		startLine = 1
+	}
+	if endLine != 0 && endLine < startLine {
+		return nil, fmt.Errorf("%s: end_line (%d) is before start_line (%d)", t.Name(), endLine, startLine)
+	}
+
+	p, err := t.ts.resolve(fileArg)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", t.Name(), err)
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to read file '%s': %w", t.Name(), fileArg, err)
+	}
+	defer f.Close()
+	if info, err := f.Stat(); err == nil && info.IsDir() {
+		return nil, fmt.Errorf("%s: '%s' is a directory", t.Name(), fileArg)
+	}
+
+	var sb strings.Builder
+	truncated := false
+	lastLine := 0
+	reader := bufio.NewReader(f)
+	for lineNo := 1; ; lineNo++ {
+		// Lines outside the range are skipped without being kept, and lines
+		// inside it are only kept up to the remaining byte budget.
+		var limit int64
+		inRange := lineNo >= startLine && (endLine == 0 || lineNo <= endLine)
+		if inRange {
+			limit = t.ts.maxReadBytes - int64(sb.Len())
+		}
+		line, n, readErr := readLine(reader, limit)
+		if n == 0 && readErr != nil {
+			break
+		}
+		lastLine = lineNo
+		if inRange {
+			if n > len(line) {
+				truncated = true
+				lastLine = lineNo - 1
+				break
+			}
+			sb.WriteString(line)
+		}
+		if endLine != 0 && lineNo >= endLine {
+			break
+		}
+		if readErr == io.EOF {
+			break
+		} else if readErr != nil {
+			return nil, fmt.Errorf("%s: failed to read file '%s': %w", t.Name(), fileArg, readErr)
+		}
+	}
+
+	result := map[string]any{
+		"path":       t.ts.relative(p),
+		"content":    sb.String(),
+		"start_line": startLine,
+		"end_line":   lastLine,
+	}
+	if truncated {
+		result["truncated"] = true
+		result["note"] = fmt.Sprintf("Output was limited to %d bytes. Use start_line and end_line to read the rest.", t.ts.maxReadBytes)
+	}
+	return result, nil
+}
+
+// readLine reads the next line from r, including its newline, and returns at
+// most limit bytes of it together with the full length of the line. The rest
+// of an over-long line is consumed without being buffered.
+func readLine(r *bufio.Reader, limit int64) (string, int, error) {
+	var sb strings.Builder
+	n := 0
+	for {
+		chunk, err := r.ReadSlice('\n')
+		n += len(chunk)
+		if room := limit - int64(sb.Len()); room > 0 {
+			if int64(len(chunk)) > room {
+				chunk = chunk[:room]
+			}
+			sb.Write(chunk)
+		}
+		if err != bufio.ErrBufferFull {
+			return sb.String(), n, err
+		}
+	}
+}
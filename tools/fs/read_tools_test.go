@@ -0,0 +1,65 @@
+package fs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadFileByteLimit(t *testing.T) {
+	ts, _ := newWorkspace(t, WithMaxReadBytes(16))
+	files := map[string]string{
+		"short.txt": "one\ntwo\n",
+		"lines.txt": "0123456789\n0123456789\n0123456789\n",
+		"long.txt":  strings.Repeat("x", 1<<16) + "\nafter\n",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(ts.Root(), name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		args      map[string]any
+		content   string
+		endLine   int
+		truncated bool
+	}{
+		{"fits", map[string]any{"path": "short.txt"}, "one\ntwo\n", 2, false},
+		{"stops before the line that overflows", map[string]any{"path": "lines.txt"}, "0123456789\n", 1, true},
+		{"line range", map[string]any{"path": "lines.txt", "start_line": float64(2), "end_line": float64(2)}, "0123456789\n", 2, false},
+		{"over-long line", map[string]any{"path": "long.txt"}, "", 0, true},
+		{"skips an over-long line", map[string]any{"path": "long.txt", "start_line": float64(2)}, "after\n", 2, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool := &ReadFileTool{ts: ts}
+			result, err := tool.Execute(context.Background(), tt.args)
+			if err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+			m := result.(map[string]any)
+			if m["content"] != tt.content {
+				t.Errorf("content = %q, want %q", m["content"], tt.content)
+			}
+			if m["end_line"] != tt.endLine {
+				t.Errorf("end_line = %v, want %d", m["end_line"], tt.endLine)
+			}
+			if truncated := m["truncated"] == true; truncated != tt.truncated {
+				t.Errorf("truncated = %v, want %v", truncated, tt.truncated)
+			}
+		})
+	}
+}
+
+func TestReadFileThroughSymlinkOutside(t *testing.T) {
+	ts, outside := newWorkspace(t)
+	symlink(t, filepath.Join(outside, "secret.txt"), filepath.Join(ts.Root(), "link.txt"))
+	tool := &ReadFileTool{ts: ts}
+	if _, err := tool.Execute(context.Background(), map[string]any{"path": "link.txt"}); err == nil {
+		t.Error("read a file outside the root through a symlink")
+	}
+}
@@ -0,0 +1,228 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/KennethanCeyer/adk-go/tools"
+)
+
+const (
+	defaultMaxReadBytes  = 1 << 20 // 1 MiB
+	defaultMaxWriteBytes = 1 << 20 // 1 MiB
+	maxListEntries       = 1000
+	maxGrepMatches       = 200
+)
+
+// Toolset is a set of filesystem tools confined to a root directory. Every
+// path argument is interpreted relative to the root, and paths that escape
+// it, either lexically or through symlinks, are rejected.
+type Toolset struct {
+	root          string
+	readOnly      bool
+	maxReadBytes  int64
+	maxWriteBytes int64
+	confirmWrites tools.ConfirmationPolicy
+}
+
+// Option configures a Toolset.
+type Option func(*Toolset)
+
+// WithReadOnly removes every tool that modifies the filesystem.
+func WithReadOnly() Option {
+	return func(ts *Toolset) { ts.readOnly = true }
+}
+
+// WithMaxReadBytes limits how much of a file read_file and grep will load.
+func WithMaxReadBytes(n int64) Option {
+	return func(ts *Toolset) { ts.maxReadBytes = n }
+}
+
+// WithMaxWriteBytes limits the size of files produced by write_file and edit_file.
+func WithMaxWriteBytes(n int64) Option {
+	return func(ts *Toolset) { ts.maxWriteBytes = n }
+}
+
+// WithWriteConfirmation makes the tools that modify the filesystem ask the
+// user for confirmation whenever policy requires it.
+func WithWriteConfirmation(policy tools.ConfirmationPolicy) Option {
+	return func(ts *Toolset) { ts.confirmWrites = policy }
+}
+
+// NewToolset creates a toolset rooted at the given directory.
+func NewToolset(root string, opts ...Option) (*Toolset, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("fs: invalid root '%s': %w", root, err)
+	}
+	realRoot, err := filepath.EvalSymlinks(absRoot)
+	if err != nil {
+		return nil, fmt.Errorf("fs: cannot resolve root '%s': %w", root, err)
+	}
+	info, err := os.Stat(realRoot)
+	if err != nil {
+		return nil, fmt.Errorf("fs: cannot access root '%s': %w", root, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("fs: root '%s' is not a directory", root)
+	}
+
+	ts := &Toolset{
+		root:          realRoot,
+		maxReadBytes:  defaultMaxReadBytes,
+		maxWriteBytes: defaultMaxWriteBytes,
+	}
+	for _, opt := range opts {
+		opt(ts)
+	}
+	return ts, nil
+}
+
+// Root returns the resolved root directory.
+func (ts *Toolset) Root() string { return ts.root }
+
+// Tools returns the tools of the set. In read-only mode only the tools that
+// inspect the filesystem are included.
+func (ts *Toolset) Tools() []tools.Tool {
+	result := []tools.Tool{
+		&ListDirTool{ts: ts},
+		&GlobTool{ts: ts},
+		&GrepTool{ts: ts},
+		&ReadFileTool{ts: ts},
+	}
+	if !ts.readOnly {
+		result = append(result,
+			&WriteFileTool{ts: ts},
+			&EditFileTool{ts: ts},
+			&MoveFileTool{ts: ts},
+			&DeleteFileTool{ts: ts},
+		)
+	}
+	return result
+}
+
+// GetTools implements tools.Toolset.
+func (ts *Toolset) GetTools(ctx context.Context) ([]tools.Tool, error) {
+	return ts.Tools(), nil
+}
+
+// resolve maps a user-supplied path onto the filesystem and verifies that the
+// result, after following any symlinks, stays inside the root.
+func (ts *Toolset) resolve(path string) (string, error) {
+	if path == "" {
+		path = "."
+	}
+	if filepath.IsAbs(path) {
+		return "", fmt.Errorf("path '%s' must be relative to the workspace root", path)
+	}
+	joined := filepath.Join(ts.root, filepath.FromSlash(path))
+	if !ts.contains(joined) {
+		return "", fmt.Errorf("path '%s' escapes the workspace root", path)
+	}
+
+	// Resolve symlinks on the longest existing prefix, so that paths which do
+	// not exist yet (e.g. write targets) are still checked through any
+	// symlinked parent directories.
+	existing := joined
+	var rest []string
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("cannot access '%s': %w", path, err)
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		rest = append([]string{filepath.Base(existing)}, rest...)
+		existing = parent
+	}
+	realExisting, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		return "", fmt.Errorf("cannot resolve '%s': %w", path, err)
+	}
+	resolved := filepath.Join(append([]string{realExisting}, rest...)...)
+	if !ts.contains(resolved) {
+		return "", fmt.Errorf("path '%s' escapes the workspace root", path)
+	}
+	return resolved, nil
+}
+
+func (ts *Toolset) contains(path string) bool {
+	rel, err := filepath.Rel(ts.root, path)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
+// relative converts a resolved path back into the root-relative form shown to the model.
+func (ts *Toolset) relative(path string) string {
+	rel, err := filepath.Rel(ts.root, path)
+	if err != nil {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
+func (ts *Toolset) checkWritable(toolName string) error {
+	if ts.readOnly {
+		return fmt.Errorf("%s: the workspace is read-only", toolName)
+	}
+	return nil
+}
+
+func argsMap(toolName string, args any) (map[string]any, error) {
+	m, ok := args.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s: invalid arguments format, expected map[string]any, got %T", toolName, args)
+	}
+	return m, nil
+}
+
+func stringArg(toolName string, args map[string]any, name string, required bool) (string, error) {
+	val, ok := args[name]
+	if !ok || val == nil {
+		if required {
+			return "", fmt.Errorf("%s: missing '%s' argument", toolName, name)
+		}
+		return "", nil
+	}
+	s, ok := val.(string)
+	if !ok {
+		return "", fmt.Errorf("%s: '%s' argument must be a string, got %T", toolName, name, val)
+	}
+	return s, nil
+}
+
+func intArg(toolName string, args map[string]any, name string) (int, error) {
+	val, ok := args[name]
+	if !ok || val == nil {
+		return 0, nil
+	}
+	switch v := val.(type) {
+	case float64:
+		return int(v), nil
+	case int:
+		return v, nil
+	default:
+		return 0, fmt.Errorf("%s: '%s' argument must be a number, got %T", toolName, name, val)
+	}
+}
+
+func boolArg(toolName string, args map[string]any, name string) (bool, error) {
+	val, ok := args[name]
+	if !ok || val == nil {
+		return false, nil
+	}
+	b, ok := val.(bool)
+	if !ok {
+		return false, fmt.Errorf("%s: '%s' argument must be a boolean, got %T", toolName, name, val)
+	}
+	return b, nil
+}
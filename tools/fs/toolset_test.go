@@ -0,0 +1,102 @@
+package fs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newWorkspace creates a root directory with a file and a directory inside
+// it, plus a sibling directory outside it that symlinks can point at.
+func newWorkspace(t *testing.T, opts ...Option) (*Toolset, string) {
+	t.Helper()
+	base := t.TempDir()
+	root := filepath.Join(base, "root")
+	outside := filepath.Join(base, "outside")
+	for _, dir := range []string{filepath.Join(root, "dir"), outside} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "dir", "file.txt"), []byte("inside\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ts, err := NewToolset(root, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ts, outside
+}
+
+func symlink(t *testing.T, target, link string) {
+	t.Helper()
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	ts, outside := newWorkspace(t)
+	symlink(t, outside, filepath.Join(ts.Root(), "escape"))
+	symlink(t, filepath.Join(outside, "missing.txt"), filepath.Join(ts.Root(), "dangling"))
+	symlink(t, "dir", filepath.Join(ts.Root(), "alias"))
+
+	tests := []struct {
+		name string
+		path string
+		want string // relative to the root; empty means the path is rejected
+	}{
+		{"empty is the root", "", "."},
+		{"plain file", "dir/file.txt", "dir/file.txt"},
+		{"missing file", "dir/new/file.txt", "dir/new/file.txt"},
+		{"dot dot inside", "dir/../dir/file.txt", "dir/file.txt"},
+		{"dot dot escape", "../outside/secret.txt", ""},
+		{"nested dot dot escape", "dir/../../outside/secret.txt", ""},
+		{"absolute path", filepath.Join(outside, "secret.txt"), ""},
+		{"symlinked directory outside", "escape/secret.txt", ""},
+		{"write below symlinked directory outside", "escape/new.txt", ""},
+		{"dangling symlink as write target", "dangling", ""},
+		{"symlink inside the root", "alias/file.txt", "dir/file.txt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ts.resolve(tt.path)
+			if tt.want == "" {
+				if err == nil {
+					t.Errorf("resolve(%q) = %q, want an error", tt.path, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolve(%q): %v", tt.path, err)
+			}
+			if rel := ts.relative(got); rel != tt.want {
+				t.Errorf("resolve(%q) = %q, want %q", tt.path, rel, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	ts, _ := newWorkspace(t, WithReadOnly())
+	for _, tool := range ts.Tools() {
+		switch tool.Name() {
+		case "write_file", "edit_file", "move_file", "delete_file":
+			t.Errorf("read-only toolset offers %s", tool.Name())
+		}
+	}
+
+	write := &WriteFileTool{ts: ts}
+	_, err := write.Execute(context.Background(), map[string]any{"path": "new.txt", "content": "x"})
+	if err == nil || !strings.Contains(err.Error(), "read-only") {
+		t.Errorf("err = %v, want a read-only error", err)
+	}
+	if _, err := os.Stat(filepath.Join(ts.Root(), "new.txt")); !os.IsNotExist(err) {
+		t.Errorf("file was written in read-only mode")
+	}
+}
@@ -0,0 +1,342 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type WriteFileTool struct{ ts *Toolset }
+
+func (t *WriteFileTool) Name() string { return "write_file" }
+
+func (t *WriteFileTool) Description() string {
+	return "Writes content to a file in the workspace, creating parent directories and overwriting the file if it exists."
+}
+
+func (t *WriteFileTool) RequiresConfirmation(args map[string]any) bool {
+	return t.ts.requiresConfirmation(args)
+}
+
+func (t *WriteFileTool) Parameters() any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"path": map[string]any{
+				"type":        "string",
+				"description": "File path relative to the workspace root.",
+			},
+			"content": map[string]any{
+				"type":        "string",
+				"description": "The content to write to the file.",
+			},
+		},
+		"required": []string{"path", "content"},
+	}
+}
+
+func (t *WriteFileTool) Execute(ctx context.Context, args any) (any, error) {
+	if err := t.ts.checkWritable(t.Name()); err != nil {
+		return nil, err
+	}
+	argMap, err := argsMap(t.Name(), args)
+	if err != nil {
+		return nil, err
+	}
+	fileArg, err := stringArg(t.Name(), argMap, "path", true)
+	if err != nil {
+		return nil, err
+	}
+	content, err := stringArg(t.Name(), argMap, "content", true)
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(content)) > t.ts.maxWriteBytes {
+		return nil, fmt.Errorf("%s: content is %d bytes, exceeding the %d byte limit", t.Name(), len(content), t.ts.maxWriteBytes)
+	}
+
+	p, err := t.ts.resolve(fileArg)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", t.Name(), err)
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return nil, fmt.Errorf("%s: failed to create parent directories for '%s': %w", t.Name(), fileArg, err)
+	}
+	if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+		return nil, fmt.Errorf("%s: failed to write to file '%s': %w", t.Name(), fileArg, err)
+	}
+	return map[string]any{
+		"status":  "success",
+		"message": fmt.Sprintf("Successfully wrote %d bytes to %s", len(content), t.ts.relative(p)),
+	}, nil
+}
+
+type EditFileTool struct{ ts *Toolset }
+
+func (t *EditFileTool) Name() string { return "edit_file" }
+
+func (t *EditFileTool) Description() string {
+	return "Makes a targeted edit to a file by replacing an exact snippet of existing text. The snippet must match exactly once unless replace_all is set."
+}
+
+func (t *EditFileTool) RequiresConfirmation(args map[string]any) bool {
+	return t.ts.requiresConfirmation(args)
+}
+
+func (t *EditFileTool) Parameters() any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"path": map[string]any{
+				"type":        "string",
+				"description": "File path relative to the workspace root.",
+			},
+			"old_text": map[string]any{
+				"type":        "string",
+				"description": "The exact text to replace, including whitespace.",
+			},
+			"new_text": map[string]any{
+				"type":        "string",
+				"description": "The replacement text.",
+			},
+			"replace_all": map[string]any{
+				"type":        "boolean",
+				"description": "Replace every occurrence instead of requiring a unique match.",
+			},
+		},
+		"required": []string{"path", "old_text", "new_text"},
+	}
+}
+
+func (t *EditFileTool) Execute(ctx context.Context, args any) (any, error) {
+	if err := t.ts.checkWritable(t.Name()); err != nil {
+		return nil, err
+	}
+	argMap, err := argsMap(t.Name(), args)
+	if err != nil {
+		return nil, err
+	}
+	fileArg, err := stringArg(t.Name(), argMap, "path", true)
+	if err != nil {
+		return nil, err
+	}
+	oldText, err := stringArg(t.Name(), argMap, "old_text", true)
+	if err != nil {
+		return nil, err
+	}
+	newText, err := stringArg(t.Name(), argMap, "new_text", true)
+	if err != nil {
+		return nil, err
+	}
+	replaceAll, err := boolArg(t.Name(), argMap, "replace_all")
+	if err != nil {
+		return nil, err
+	}
+	if oldText == "" {
+		return nil, fmt.Errorf("%s: old_text cannot be empty", t.Name())
+	}
+
+	p, err := t.ts.resolve(fileArg)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", t.Name(), err)
+	}
+	info, err := os.Stat(p)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to read file '%s': %w", t.Name(), fileArg, err)
+	}
+	if info.Size() > t.ts.maxReadBytes {
+		return nil, fmt.Errorf("%s: file '%s' is %d bytes, exceeding the %d byte limit", t.Name(), fileArg, info.Size(), t.ts.maxReadBytes)
+	}
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to read file '%s': %w", t.Name(), fileArg, err)
+	}
+	content := string(data)
+
+	count := strings.Count(content, oldText)
+	switch {
+	case count == 0:
+		return nil, fmt.Errorf("%s: old_text was not found in '%s'", t.Name(), fileArg)
+	case count > 1 && !replaceAll:
+		return nil, fmt.Errorf("%s: old_text matches %d times in '%s'; add surrounding context or set replace_all", t.Name(), count, fileArg)
+	}
+	n := 1
+	if replaceAll {
+		n = -1
+	}
+	updated := strings.Replace(content, oldText, newText, n)
+	if int64(len(updated)) > t.ts.maxWriteBytes {
+		return nil, fmt.Errorf("%s: edited file would be %d bytes, exceeding the %d byte limit", t.Name(), len(updated), t.ts.maxWriteBytes)
+	}
+	if err := os.WriteFile(p, []byte(updated), info.Mode().Perm()); err != nil {
+		return nil, fmt.Errorf("%s: failed to write to file '%s': %w", t.Name(), fileArg, err)
+	}
+	replaced := 1
+	if replaceAll {
+		replaced = count
+	}
+	return map[string]any{
+		"status":       "success",
+		"replacements": replaced,
+	}, nil
+}
+
+type MoveFileTool struct{ ts *Toolset }
+
+func (t *MoveFileTool) Name() string { return "move_file" }
+
+func (t *MoveFileTool) Description() string {
+	return "Moves or renames a file or directory within the workspace. Fails if the destination already exists."
+}
+
+func (t *MoveFileTool) RequiresConfirmation(args map[string]any) bool {
+	return t.ts.requiresConfirmation(args)
+}
+
+func (t *MoveFileTool) Parameters() any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"source": map[string]any{
+				"type":        "string",
+				"description": "Existing path relative to the workspace root.",
+			},
+			"destination": map[string]any{
+				"type":        "string",
+				"description": "New path relative to the workspace root.",
+			},
+		},
+		"required": []string{"source", "destination"},
+	}
+}
+
+func (t *MoveFileTool) Execute(ctx context.Context, args any) (any, error) {
+	if err := t.ts.checkWritable(t.Name()); err != nil {
+		return nil, err
+	}
+	argMap, err := argsMap(t.Name(), args)
+	if err != nil {
+		return nil, err
+	}
+	srcArg, err := stringArg(t.Name(), argMap, "source", true)
+	if err != nil {
+		return nil, err
+	}
+	dstArg, err := stringArg(t.Name(), argMap, "destination", true)
+	if err != nil {
+		return nil, err
+	}
+	// Resolve the parent of the source only, so that moving a symlink moves
+	// the link itself rather than its target.
+	srcParent, err := t.ts.resolve(filepath.Dir(filepath.FromSlash(srcArg)))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", t.Name(), err)
+	}
+	src := filepath.Join(srcParent, filepath.Base(srcArg))
+	if src == t.ts.root || !t.ts.contains(src) {
+		return nil, fmt.Errorf("%s: cannot move '%s'", t.Name(), srcArg)
+	}
+	dst, err := t.ts.resolve(dstArg)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", t.Name(), err)
+	}
+	if _, err := os.Lstat(dst); err == nil {
+		return nil, fmt.Errorf("%s: destination '%s' already exists", t.Name(), dstArg)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%s: cannot access destination '%s': %w", t.Name(), dstArg, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return nil, fmt.Errorf("%s: failed to create parent directories for '%s': %w", t.Name(), dstArg, err)
+	}
+	if err := os.Rename(src, dst); err != nil {
+		return nil, fmt.Errorf("%s: failed to move '%s' to '%s': %w", t.Name(), srcArg, dstArg, err)
+	}
+	return map[string]any{
+		"status":  "success",
+		"message": fmt.Sprintf("Moved %s to %s", t.ts.relative(src), t.ts.relative(dst)),
+	}, nil
+}
+
+type DeleteFileTool struct{ ts *Toolset }
+
+func (t *DeleteFileTool) Name() string { return "delete_file" }
+
+func (t *DeleteFileTool) Description() string {
+	return "Deletes a file in the workspace. Directories are only deleted when recursive is set."
+}
+
+func (t *DeleteFileTool) RequiresConfirmation(args map[string]any) bool {
+	return t.ts.requiresConfirmation(args)
+}
+
+func (t *DeleteFileTool) Parameters() any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"path": map[string]any{
+				"type":        "string",
+				"description": "Path relative to the workspace root.",
+			},
+			"recursive": map[string]any{
+				"type":        "boolean",
+				"description": "Delete a directory and everything inside it.",
+			},
+		},
+		"required": []string{"path"},
+	}
+}
+
+func (t *DeleteFileTool) Execute(ctx context.Context, args any) (any, error) {
+	if err := t.ts.checkWritable(t.Name()); err != nil {
+		return nil, err
+	}
+	argMap, err := argsMap(t.Name(), args)
+	if err != nil {
+		return nil, err
+	}
+	fileArg, err := stringArg(t.Name(), argMap, "path", true)
+	if err != nil {
+		return nil, err
+	}
+	recursive, err := boolArg(t.Name(), argMap, "recursive")
+	if err != nil {
+		return nil, err
+	}
+
+	// Resolve the parent only, so that deleting a symlink removes the link
+	// itself rather than its target.
+	parent, err := t.ts.resolve(filepath.Dir(filepath.FromSlash(fileArg)))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", t.Name(), err)
+	}
+	p := filepath.Join(parent, filepath.Base(fileArg))
+	if p == t.ts.root || !t.ts.contains(p) {
+		return nil, fmt.Errorf("%s: refusing to delete '%s'", t.Name(), fileArg)
+	}
+	info, err := os.Lstat(p)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to delete '%s': %w", t.Name(), fileArg, err)
+	}
+	if info.IsDir() {
+		if !recursive {
+			return nil, fmt.Errorf("%s: '%s' is a directory; set recursive to delete it", t.Name(), fileArg)
+		}
+		err = os.RemoveAll(p)
+	} else {
+		err = os.Remove(p)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to delete '%s': %w", t.Name(), fileArg, err)
+	}
+	return map[string]any{
+		"status":  "success",
+		"message": fmt.Sprintf("Deleted %s", t.ts.relative(p)),
+	}, nil
+}
+
+func (ts *Toolset) requiresConfirmation(args map[string]any) bool {
+	return ts.confirmWrites != nil && ts.confirmWrites(args)
+}
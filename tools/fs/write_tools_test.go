@@ -0,0 +1,92 @@
+package fs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteFileByteLimit(t *testing.T) {
+	ts, _ := newWorkspace(t, WithMaxWriteBytes(4))
+	tool := &WriteFileTool{ts: ts}
+	if _, err := tool.Execute(context.Background(), map[string]any{"path": "ok.txt", "content": "four"}); err != nil {
+		t.Errorf("write within the limit: %v", err)
+	}
+	_, err := tool.Execute(context.Background(), map[string]any{"path": "big.txt", "content": "five!"})
+	if err == nil || !strings.Contains(err.Error(), "byte limit") {
+		t.Errorf("err = %v, want a byte limit error", err)
+	}
+	if _, err := os.Stat(filepath.Join(ts.Root(), "big.txt")); !os.IsNotExist(err) {
+		t.Error("file over the limit was written")
+	}
+}
+
+func TestWriteFileThroughDanglingSymlink(t *testing.T) {
+	ts, outside := newWorkspace(t)
+	target := filepath.Join(outside, "created.txt")
+	symlink(t, target, filepath.Join(ts.Root(), "dangling"))
+	tool := &WriteFileTool{ts: ts}
+	if _, err := tool.Execute(context.Background(), map[string]any{"path": "dangling", "content": "x"}); err == nil {
+		t.Error("wrote through a dangling symlink")
+	}
+	if _, err := os.Stat(target); !os.IsNotExist(err) {
+		t.Error("file was created outside the root")
+	}
+}
+
+func TestDeleteFileRemovesSymlinkNotTarget(t *testing.T) {
+	ts, outside := newWorkspace(t)
+	secret := filepath.Join(outside, "secret.txt")
+	link := filepath.Join(ts.Root(), "link")
+	symlink(t, outside, link)
+
+	tool := &DeleteFileTool{ts: ts}
+	if _, err := tool.Execute(context.Background(), map[string]any{"path": "link", "recursive": true}); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if _, err := os.Lstat(link); !os.IsNotExist(err) {
+		t.Error("symlink was not removed")
+	}
+	if _, err := os.Stat(secret); err != nil {
+		t.Errorf("symlink target was touched: %v", err)
+	}
+
+	if _, err := tool.Execute(context.Background(), map[string]any{"path": "../outside/secret.txt"}); err == nil {
+		t.Error("deleted a file outside the root")
+	}
+}
+
+func TestMoveFileMovesSymlinkNotTarget(t *testing.T) {
+	ts, outside := newWorkspace(t)
+	symlink(t, filepath.Join(outside, "secret.txt"), filepath.Join(ts.Root(), "link"))
+
+	tool := &MoveFileTool{ts: ts}
+	if _, err := tool.Execute(context.Background(), map[string]any{"source": "link", "destination": "dir/moved"}); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	info, err := os.Lstat(filepath.Join(ts.Root(), "dir", "moved"))
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("destination is not the moved symlink: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outside, "secret.txt")); err != nil {
+		t.Errorf("symlink target was moved: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		args map[string]any
+	}{
+		{"source outside", map[string]any{"source": "../outside/secret.txt", "destination": "stolen.txt"}},
+		{"destination outside", map[string]any{"source": "dir/file.txt", "destination": "../outside/file.txt"}},
+		{"root as source", map[string]any{"source": ".", "destination": "dir/root"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tool.Execute(context.Background(), tt.args); err == nil {
+				t.Errorf("move %v succeeded, want an error", tt.args)
+			}
+		})
+	}
+}
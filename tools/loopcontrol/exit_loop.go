@@ -0,0 +1,48 @@
+// Package loopcontrol provides tools that let a model control the LoopAgent
+// it runs in.
+package loopcontrol
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/KennethanCeyer/adk-go/agents/invocation"
+)
+
+// ExitLoopTool lets the model end the enclosing LoopAgent once its task is
+// done. Other tools can do the same by calling Escalate on the invocation
+// context directly.
+type ExitLoopTool struct{}
+
+func NewExitLoopTool() *ExitLoopTool { return &ExitLoopTool{} }
+
+func (t *ExitLoopTool) Name() string { return "exit_loop" }
+
+func (t *ExitLoopTool) Description() string {
+	return "Ends the current loop after this turn. Call it only when the task is complete and no further iterations are needed."
+}
+
+func (t *ExitLoopTool) Parameters() any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"reason": map[string]any{
+				"type":        "string",
+				"description": "Why the loop can stop, e.g. 'the number was guessed'.",
+			},
+		},
+	}
+}
+
+func (t *ExitLoopTool) Execute(ctx context.Context, args any) (any, error) {
+	argsMap, ok := args.(map[string]any)
+	if !ok && args != nil {
+		return nil, fmt.Errorf("exit_loop: invalid arguments format, expected map[string]any, got %T", args)
+	}
+	reason, _ := argsMap["reason"].(string)
+	if reason == "" {
+		reason = "the model called exit_loop"
+	}
+	invocation.FromContext(ctx).Escalate(reason)
+	return map[string]any{"status": "success", "message": "The loop will end after this turn. Give your final answer now."}, nil
+}
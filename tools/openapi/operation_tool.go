@@ -0,0 +1,193 @@
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// bodyArg is the tool argument that carries the request body.
+const bodyArg = "body"
+
+const maxResponseBytes = 10 << 20 // 10 MiB
+
+// OperationTool performs a single OpenAPI operation over HTTP.
+type OperationTool struct {
+	name          string
+	description   string
+	method        string
+	path          string
+	baseURL       string
+	schema        map[string]any
+	params        []boundParameter
+	bodyMediaType string
+	bodyRequired  bool
+	toolset       *Toolset
+}
+
+type boundParameter struct {
+	arg   string
+	param *Parameter
+}
+
+func (t *OperationTool) Name() string { return t.name }
+
+func (t *OperationTool) Description() string { return t.description }
+
+func (t *OperationTool) Parameters() any { return t.schema }
+
+func (t *OperationTool) Execute(ctx context.Context, args any) (any, error) {
+	argsMap, ok := args.(map[string]any)
+	if !ok {
+		if args != nil {
+			return nil, fmt.Errorf("%s: invalid arguments format, expected map[string]any, got %T", t.name, args)
+		}
+		argsMap = map[string]any{}
+	}
+
+	req, err := t.buildRequest(ctx, argsMap)
+	if err != nil {
+		return nil, err
+	}
+	if t.toolset.auth != nil {
+		if err := t.toolset.auth.Apply(req); err != nil {
+			return nil, fmt.Errorf("%s: failed to apply auth: %w", t.name, err)
+		}
+	}
+
+	resp, err := t.toolset.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: request failed: %w", t.name, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to read response: %w", t.name, err)
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, fmt.Errorf("%s: %s %s returned %s: %s", t.name, t.method, req.URL.Path, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return decodeBody(resp.Header.Get("Content-Type"), body), nil
+}
+
+func (t *OperationTool) buildRequest(ctx context.Context, args map[string]any) (*http.Request, error) {
+	path := t.path
+	query := url.Values{}
+	header := http.Header{}
+
+	for _, bp := range t.params {
+		value, present := args[bp.arg]
+		if !present || value == nil {
+			if bp.param.Required || bp.param.In == "path" {
+				return nil, fmt.Errorf("%s: missing required argument '%s'", t.name, bp.arg)
+			}
+			continue
+		}
+		switch bp.param.In {
+		case "path":
+			path = strings.ReplaceAll(path, "{"+bp.param.Name+"}", url.PathEscape(formatValue(value)))
+		case "query":
+			if list, ok := value.([]any); ok {
+				for _, item := range list {
+					query.Add(bp.param.Name, formatValue(item))
+				}
+			} else {
+				query.Set(bp.param.Name, formatValue(value))
+			}
+		case "header":
+			header.Set(bp.param.Name, formatValue(value))
+		case "cookie":
+			header.Add("Cookie", (&http.Cookie{Name: bp.param.Name, Value: formatValue(value)}).String())
+		}
+	}
+
+	var bodyReader io.Reader
+	if _, present := args[bodyArg]; !present && t.bodyRequired {
+		return nil, fmt.Errorf("%s: missing required argument '%s'", t.name, bodyArg)
+	}
+	if bodyValue, present := args[bodyArg]; present && t.bodyMediaType != "" {
+		encoded, err := t.encodeBody(bodyValue)
+		if err != nil {
+			return nil, err
+		}
+		bodyReader = bytes.NewReader(encoded)
+		header.Set("Content-Type", t.bodyMediaType)
+	}
+
+	target := t.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, t.method, target, bodyReader)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to build request: %w", t.name, err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Accept", "application/json")
+	return req, nil
+}
+
+func (t *OperationTool) encodeBody(value any) ([]byte, error) {
+	if t.bodyMediaType == "application/x-www-form-urlencoded" {
+		fields, ok := value.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%s: form body must be an object, got %T", t.name, value)
+		}
+		form := url.Values{}
+		for key, v := range fields {
+			form.Set(key, formatValue(v))
+		}
+		return []byte(form.Encode()), nil
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to encode request body: %w", t.name, err)
+	}
+	return encoded, nil
+}
+
+// decodeBody returns JSON objects as-is. Other JSON values are wrapped under
+// "result" and non-JSON payloads under "content", so the result is always a map.
+func decodeBody(contentType string, body []byte) map[string]any {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return map[string]any{}
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "" || mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") {
+		var decoded any
+		if err := json.Unmarshal(body, &decoded); err == nil {
+			if m, ok := decoded.(map[string]any); ok {
+				return m
+			}
+			return map[string]any{"result": decoded}
+		}
+	}
+	return map[string]any{"content": string(body)}
+}
+
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		if v == float64(int64(v)) {
+			return fmt.Sprintf("%d", int64(v))
+		}
+		return fmt.Sprintf("%g", v)
+	case map[string]any, []any:
+		encoded, err := json.Marshal(v)
+		if err == nil {
+			return string(encoded)
+		}
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,230 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Document is the subset of an OpenAPI 3 document needed to generate tools.
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Servers    []Server             `json:"servers,omitempty"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components,omitempty"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type Server struct {
+	URL         string                    `json:"url"`
+	Description string                    `json:"description,omitempty"`
+	Variables   map[string]ServerVariable `json:"variables,omitempty"`
+}
+
+type ServerVariable struct {
+	Default string   `json:"default"`
+	Enum    []string `json:"enum,omitempty"`
+}
+
+type PathItem struct {
+	Summary     string       `json:"summary,omitempty"`
+	Description string       `json:"description,omitempty"`
+	Servers     []Server     `json:"servers,omitempty"`
+	Parameters  []*Parameter `json:"parameters,omitempty"`
+	Get         *Operation   `json:"get,omitempty"`
+	Put         *Operation   `json:"put,omitempty"`
+	Post        *Operation   `json:"post,omitempty"`
+	Delete      *Operation   `json:"delete,omitempty"`
+	Options     *Operation   `json:"options,omitempty"`
+	Head        *Operation   `json:"head,omitempty"`
+	Patch       *Operation   `json:"patch,omitempty"`
+	Trace       *Operation   `json:"trace,omitempty"`
+}
+
+// operations returns the operations of the path item keyed by HTTP method,
+// in a fixed method order.
+func (p *PathItem) operations() []methodOperation {
+	var ops []methodOperation
+	for _, mo := range []methodOperation{
+		{"GET", p.Get}, {"PUT", p.Put}, {"POST", p.Post}, {"DELETE", p.Delete},
+		{"OPTIONS", p.Options}, {"HEAD", p.Head}, {"PATCH", p.Patch}, {"TRACE", p.Trace},
+	} {
+		if mo.op != nil {
+			ops = append(ops, mo)
+		}
+	}
+	return ops
+}
+
+type methodOperation struct {
+	method string
+	op     *Operation
+}
+
+type Operation struct {
+	OperationID string       `json:"operationId,omitempty"`
+	Summary     string       `json:"summary,omitempty"`
+	Description string       `json:"description,omitempty"`
+	Parameters  []*Parameter `json:"parameters,omitempty"`
+	RequestBody *RequestBody `json:"requestBody,omitempty"`
+	Servers     []Server     `json:"servers,omitempty"`
+	Deprecated  bool         `json:"deprecated,omitempty"`
+}
+
+type Parameter struct {
+	Ref         string         `json:"$ref,omitempty"`
+	Name        string         `json:"name"`
+	In          string         `json:"in"`
+	Description string         `json:"description,omitempty"`
+	Required    bool           `json:"required,omitempty"`
+	Schema      map[string]any `json:"schema,omitempty"`
+}
+
+type RequestBody struct {
+	Ref         string               `json:"$ref,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Required    bool                 `json:"required,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema map[string]any `json:"schema,omitempty"`
+}
+
+type Components struct {
+	Schemas       map[string]map[string]any `json:"schemas,omitempty"`
+	Parameters    map[string]*Parameter     `json:"parameters,omitempty"`
+	RequestBodies map[string]*RequestBody   `json:"requestBodies,omitempty"`
+}
+
+// Parse decodes an OpenAPI 3 document in either JSON or YAML form.
+func Parse(data []byte) (*Document, error) {
+	var raw any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("openapi: failed to parse document: %w", err)
+	}
+	// Round-trip through JSON so that a single set of struct tags covers
+	// both input formats.
+	jsonBytes, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("openapi: failed to normalize document: %w", err)
+	}
+	var doc Document
+	if err := json.Unmarshal(jsonBytes, &doc); err != nil {
+		return nil, fmt.Errorf("openapi: failed to decode document: %w", err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return nil, fmt.Errorf("openapi: unsupported document version %q, only OpenAPI 3.x is supported", doc.OpenAPI)
+	}
+	if len(doc.Paths) == 0 {
+		return nil, fmt.Errorf("openapi: document declares no paths")
+	}
+	return &doc, nil
+}
+
+// LoadFile reads and parses an OpenAPI 3 document from disk.
+func LoadFile(path string) (*Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("openapi: failed to read '%s': %w", path, err)
+	}
+	return Parse(data)
+}
+
+const refPrefix = "#/components/"
+
+func (d *Document) resolveParameter(p *Parameter) (*Parameter, error) {
+	if p == nil || p.Ref == "" {
+		return p, nil
+	}
+	name := strings.TrimPrefix(p.Ref, refPrefix+"parameters/")
+	resolved, ok := d.Components.Parameters[name]
+	if !ok || name == p.Ref {
+		return nil, fmt.Errorf("openapi: unresolvable parameter reference %q", p.Ref)
+	}
+	return d.resolveParameter(resolved)
+}
+
+func (d *Document) resolveRequestBody(b *RequestBody) (*RequestBody, error) {
+	if b == nil || b.Ref == "" {
+		return b, nil
+	}
+	name := strings.TrimPrefix(b.Ref, refPrefix+"requestBodies/")
+	resolved, ok := d.Components.RequestBodies[name]
+	if !ok || name == b.Ref {
+		return nil, fmt.Errorf("openapi: unresolvable request body reference %q", b.Ref)
+	}
+	return d.resolveRequestBody(resolved)
+}
+
+// maxSchemaDepth bounds $ref expansion so recursive schemas terminate.
+const maxSchemaDepth = 5
+
+// resolveSchema returns a copy of the schema with local component references
+// inlined, since function-calling models cannot follow $ref pointers.
+func (d *Document) resolveSchema(schema map[string]any, depth int) map[string]any {
+	if schema == nil {
+		return nil
+	}
+	if ref, ok := schema["$ref"].(string); ok {
+		name := strings.TrimPrefix(ref, refPrefix+"schemas/")
+		target, found := d.Components.Schemas[name]
+		if !found || name == ref || depth >= maxSchemaDepth {
+			return map[string]any{"type": "object"}
+		}
+		return d.resolveSchema(target, depth+1)
+	}
+
+	out := make(map[string]any, len(schema))
+	for key, val := range schema {
+		switch key {
+		case "properties":
+			props, ok := val.(map[string]any)
+			if !ok {
+				continue
+			}
+			resolvedProps := make(map[string]any, len(props))
+			for name, prop := range props {
+				if propSchema, ok := prop.(map[string]any); ok {
+					resolvedProps[name] = d.resolveSchema(propSchema, depth+1)
+				}
+			}
+			out[key] = resolvedProps
+		case "items", "not":
+			if items, ok := val.(map[string]any); ok {
+				out[key] = d.resolveSchema(items, depth+1)
+			}
+		case "additionalProperties":
+			// May also be a boolean.
+			if additional, ok := val.(map[string]any); ok {
+				out[key] = d.resolveSchema(additional, depth+1)
+			} else {
+				out[key] = val
+			}
+		case "allOf", "oneOf", "anyOf":
+			variants, ok := val.([]any)
+			if !ok {
+				continue
+			}
+			resolvedVariants := make([]any, 0, len(variants))
+			for _, variant := range variants {
+				if variantSchema, ok := variant.(map[string]any); ok {
+					resolvedVariants = append(resolvedVariants, d.resolveSchema(variantSchema, depth+1))
+				}
+			}
+			out[key] = resolvedVariants
+		default:
+			out[key] = val
+		}
+	}
+	return out
+}
@@ -0,0 +1,289 @@
+package openapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/KennethanCeyer/adk-go/tools"
+)
+
+// Toolset turns every operation of an OpenAPI document into a tools.Tool.
+type Toolset struct {
+	doc             *Document
+	baseURL         string
+	serverIndex     int
+	serverVariables map[string]string
+	auth            Auth
+	client          *http.Client
+	tools           []tools.Tool
+}
+
+// Option configures a Toolset.
+type Option func(*Toolset)
+
+// WithBaseURL overrides the server URLs declared in the document.
+func WithBaseURL(baseURL string) Option {
+	return func(ts *Toolset) { ts.baseURL = baseURL }
+}
+
+// WithServerIndex selects which entry of the document's servers list to use.
+func WithServerIndex(index int) Option {
+	return func(ts *Toolset) { ts.serverIndex = index }
+}
+
+// WithServerVariables overrides the default values of server URL variables.
+func WithServerVariables(vars map[string]string) Option {
+	return func(ts *Toolset) { ts.serverVariables = vars }
+}
+
+// WithAuth sets the credentials applied to every request.
+func WithAuth(auth Auth) Option {
+	return func(ts *Toolset) { ts.auth = auth }
+}
+
+// WithHTTPClient sets the client used to perform requests.
+func WithHTTPClient(client *http.Client) Option {
+	return func(ts *Toolset) { ts.client = client }
+}
+
+// NewToolset builds one tool per operation declared in the document.
+func NewToolset(doc *Document, opts ...Option) (*Toolset, error) {
+	if doc == nil {
+		return nil, fmt.Errorf("openapi: document cannot be nil")
+	}
+	ts := &Toolset{
+		doc:    doc,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+	for _, opt := range opts {
+		opt(ts)
+	}
+
+	paths := make([]string, 0, len(doc.Paths))
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		item := doc.Paths[path]
+		if item == nil {
+			continue
+		}
+		for _, mo := range item.operations() {
+			tool, err := ts.newOperationTool(path, item, mo.method, mo.op)
+			if err != nil {
+				return nil, err
+			}
+			if seen[tool.name] {
+				return nil, fmt.Errorf("openapi: duplicate tool name '%s' for %s %s", tool.name, mo.method, path)
+			}
+			seen[tool.name] = true
+			ts.tools = append(ts.tools, tool)
+		}
+	}
+	return ts, nil
+}
+
+// Tools returns the generated tools, sorted by path and method.
+func (ts *Toolset) Tools() []tools.Tool {
+	out := make([]tools.Tool, len(ts.tools))
+	copy(out, ts.tools)
+	return out
+}
+
+// GetTools implements tools.Toolset.
+func (ts *Toolset) GetTools(ctx context.Context) ([]tools.Tool, error) {
+	return ts.Tools(), nil
+}
+
+func (ts *Toolset) newOperationTool(path string, item *PathItem, method string, op *Operation) (*OperationTool, error) {
+	name := op.OperationID
+	if name == "" {
+		name = strings.ToLower(method) + "_" + path
+	}
+	name = sanitizeName(name)
+
+	baseURL, err := ts.serverURL(op, item)
+	if err != nil {
+		return nil, fmt.Errorf("openapi: operation '%s': %w", name, err)
+	}
+
+	// Path-level parameters apply to every operation unless overridden.
+	params := make(map[string]*Parameter)
+	var order []string
+	for _, raw := range append(append([]*Parameter{}, item.Parameters...), op.Parameters...) {
+		p, err := ts.doc.resolveParameter(raw)
+		if err != nil {
+			return nil, fmt.Errorf("openapi: operation '%s': %w", name, err)
+		}
+		if p == nil || ignoredHeader(p) {
+			continue
+		}
+		key := p.In + ":" + p.Name
+		if _, exists := params[key]; !exists {
+			order = append(order, key)
+		}
+		params[key] = p
+	}
+
+	tool := &OperationTool{
+		name:    name,
+		method:  method,
+		path:    path,
+		baseURL: baseURL,
+		toolset: ts,
+	}
+	tool.description = op.Summary
+	if op.Description != "" {
+		if tool.description != "" {
+			tool.description += "\n\n"
+		}
+		tool.description += op.Description
+	}
+	if tool.description == "" {
+		tool.description = fmt.Sprintf("Calls %s %s.", method, path)
+	}
+
+	properties := map[string]any{}
+	var required []string
+	for _, key := range order {
+		p := params[key]
+		argName := sanitizeName(p.Name)
+		if _, taken := properties[argName]; taken || argName == bodyArg {
+			argName = sanitizeName(p.In + "_" + p.Name)
+		}
+		schema := ts.doc.resolveSchema(p.Schema, 0)
+		if schema == nil {
+			schema = map[string]any{"type": "string"}
+		}
+		if p.Description != "" {
+			schema["description"] = p.Description
+		}
+		properties[argName] = schema
+		if p.Required || p.In == "path" {
+			required = append(required, argName)
+		}
+		tool.params = append(tool.params, boundParameter{arg: argName, param: p})
+	}
+
+	body, err := ts.doc.resolveRequestBody(op.RequestBody)
+	if err != nil {
+		return nil, fmt.Errorf("openapi: operation '%s': %w", name, err)
+	}
+	if body != nil {
+		mediaType, media, ok := pickMediaType(body.Content)
+		if ok {
+			schema := ts.doc.resolveSchema(media.Schema, 0)
+			if schema == nil {
+				schema = map[string]any{"type": "object"}
+			}
+			if body.Description != "" {
+				schema["description"] = body.Description
+			}
+			properties[bodyArg] = schema
+			if body.Required {
+				required = append(required, bodyArg)
+			}
+			tool.bodyRequired = body.Required
+			tool.bodyMediaType = mediaType
+		}
+	}
+
+	schema := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	tool.schema = schema
+	return tool, nil
+}
+
+// serverURL picks the base URL for an operation. An explicit base URL wins,
+// then operation-level, path-level and finally document-level servers.
+func (ts *Toolset) serverURL(op *Operation, item *PathItem) (string, error) {
+	if ts.baseURL != "" {
+		return strings.TrimRight(ts.baseURL, "/"), nil
+	}
+	servers := ts.doc.Servers
+	if len(item.Servers) > 0 {
+		servers = item.Servers
+	}
+	if len(op.Servers) > 0 {
+		servers = op.Servers
+	}
+	if len(servers) == 0 {
+		return "", fmt.Errorf("no servers declared, use WithBaseURL to set one")
+	}
+	index := ts.serverIndex
+	if index < 0 || index >= len(servers) {
+		index = 0
+	}
+	server := servers[index]
+
+	serverURL := server.URL
+	for name, variable := range server.Variables {
+		value := variable.Default
+		if override, ok := ts.serverVariables[name]; ok {
+			value = override
+		}
+		serverURL = strings.ReplaceAll(serverURL, "{"+name+"}", value)
+	}
+	parsed, err := url.Parse(serverURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid server URL %q: %w", serverURL, err)
+	}
+	if !parsed.IsAbs() {
+		return "", fmt.Errorf("server URL %q is relative, use WithBaseURL to set an absolute one", serverURL)
+	}
+	return strings.TrimRight(serverURL, "/"), nil
+}
+
+// pickMediaType prefers JSON bodies, then form bodies.
+func pickMediaType(content map[string]MediaType) (string, MediaType, bool) {
+	for _, candidate := range []string{"application/json", "application/x-www-form-urlencoded"} {
+		if media, ok := content[candidate]; ok {
+			return candidate, media, true
+		}
+	}
+	for mediaType, media := range content {
+		if strings.HasSuffix(mediaType, "+json") {
+			return mediaType, media, true
+		}
+	}
+	return "", MediaType{}, false
+}
+
+// ignoredHeader reports header parameters that OpenAPI says must be ignored,
+// because they are controlled by the content negotiation or auth settings.
+func ignoredHeader(p *Parameter) bool {
+	if p.In != "header" {
+		return false
+	}
+	switch strings.ToLower(p.Name) {
+	case "accept", "content-type", "authorization":
+		return true
+	}
+	return false
+}
+
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+
+// sanitizeName converts operation IDs and parameter names into identifiers
+// accepted by function-calling models.
+func sanitizeName(name string) string {
+	name = strings.Trim(invalidNameChars.ReplaceAllString(name, "_"), "_")
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return name
+}
@@ -0,0 +1,197 @@
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const petSpec = `
+openapi: 3.0.3
+info:
+  title: Pets
+  version: "1.0"
+paths:
+  /pets/{petId}/notes:
+    post:
+      operationId: addNote
+      parameters:
+        - name: petId
+          in: path
+          required: true
+          schema: {type: integer}
+        - name: tag
+          in: query
+          schema:
+            type: array
+            items: {type: string}
+        - name: X-Request-Id
+          in: header
+          schema: {type: string}
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Note'
+components:
+  schemas:
+    Base:
+      type: object
+      properties:
+        id: {type: string}
+    Note:
+      allOf:
+        - $ref: '#/components/schemas/Base'
+        - type: object
+          properties:
+            text: {type: string}
+            author:
+              oneOf:
+                - $ref: '#/components/schemas/Base'
+                - type: string
+            labels:
+              type: object
+              additionalProperties:
+                $ref: '#/components/schemas/Base'
+`
+
+type recordedRequest struct {
+	method string
+	path   string
+	query  map[string][]string
+	header http.Header
+	body   string
+}
+
+func newPetServer(t *testing.T) (*httptest.Server, *recordedRequest) {
+	t.Helper()
+	recorded := &recordedRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		*recorded = recordedRequest{method: r.Method, path: r.URL.Path, query: r.URL.Query(), header: r.Header.Clone(), body: string(body)}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok": true}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, recorded
+}
+
+func newPetTool(t *testing.T, opts ...Option) *OperationTool {
+	t.Helper()
+	doc, err := Parse([]byte(petSpec))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	ts, err := NewToolset(doc, opts...)
+	if err != nil {
+		t.Fatalf("NewToolset: %v", err)
+	}
+	toolList := ts.Tools()
+	if len(toolList) != 1 {
+		t.Fatalf("got %d tools, want 1", len(toolList))
+	}
+	return toolList[0].(*OperationTool)
+}
+
+func TestSchemaRefsAreResolved(t *testing.T) {
+	tool := newPetTool(t, WithBaseURL("http://example.invalid"))
+	data, err := json.Marshal(tool.Parameters())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "$ref") {
+		t.Errorf("schema still contains $ref: %s", data)
+	}
+	for _, want := range []string{`"allOf"`, `"oneOf"`, `"additionalProperties"`, `"id"`, `"text"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("schema is missing %s: %s", want, data)
+		}
+	}
+}
+
+func TestRequestMapping(t *testing.T) {
+	server, got := newPetServer(t)
+	tool := newPetTool(t, WithBaseURL(server.URL))
+
+	result, err := tool.Execute(context.Background(), map[string]any{
+		"petId":        float64(42),
+		"tag":          []any{"a", "b"},
+		"X_Request_Id": "req-1",
+		"body":         map[string]any{"text": "hello"},
+	})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if !reflect.DeepEqual(result, map[string]any{"ok": true}) {
+		t.Errorf("result = %v", result)
+	}
+	if got.method != http.MethodPost || got.path != "/pets/42/notes" {
+		t.Errorf("request = %s %s, want POST /pets/42/notes", got.method, got.path)
+	}
+	if tags := got.query["tag"]; !reflect.DeepEqual(tags, []string{"a", "b"}) {
+		t.Errorf("tag query = %v, want [a b]", tags)
+	}
+	if id := got.header.Get("X-Request-Id"); id != "req-1" {
+		t.Errorf("X-Request-Id = %q, want req-1", id)
+	}
+	if ct := got.header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if got.body != `{"text":"hello"}` {
+		t.Errorf("body = %s", got.body)
+	}
+}
+
+func TestRequestMissingRequiredArgument(t *testing.T) {
+	server, _ := newPetServer(t)
+	tool := newPetTool(t, WithBaseURL(server.URL))
+	_, err := tool.Execute(context.Background(), map[string]any{"body": map[string]any{}})
+	if err == nil || !strings.Contains(err.Error(), "missing required argument 'petId'") {
+		t.Errorf("err = %v, want a missing petId error", err)
+	}
+}
+
+func TestAuth(t *testing.T) {
+	tests := []struct {
+		name  string
+		auth  Auth
+		check func(r *recordedRequest) bool
+	}{
+		{
+			name:  "api key header",
+			auth:  &APIKeyAuth{Name: "X-API-Key", Key: "secret"},
+			check: func(r *recordedRequest) bool { return r.header.Get("X-API-Key") == "secret" },
+		},
+		{
+			name: "api key query",
+			auth: &APIKeyAuth{Name: "api_key", In: "query", Key: "secret"},
+			check: func(r *recordedRequest) bool {
+				return len(r.query["api_key"]) == 1 && r.query["api_key"][0] == "secret"
+			},
+		},
+		{
+			name:  "bearer",
+			auth:  &BearerAuth{Token: "token"},
+			check: func(r *recordedRequest) bool { return r.header.Get("Authorization") == "Bearer token" },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, got := newPetServer(t)
+			tool := newPetTool(t, WithBaseURL(server.URL), WithAuth(tt.auth))
+			_, err := tool.Execute(context.Background(), map[string]any{"petId": float64(1), "body": map[string]any{}})
+			if err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+			if !tt.check(got) {
+				t.Errorf("credentials not sent: header=%v query=%v", got.header, got.query)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package tools
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// The registry holds tools that can be referenced by name, e.g. from the
+// command line or from declarative configuration.
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Tool)
+)
+
+// RegisterTool adds tool to the registry under its name. Registering two
+// tools with the same name is an error.
+func RegisterTool(tool Tool) error {
+	if tool == nil {
+		return fmt.Errorf("cannot register a nil tool")
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, exists := registry[tool.Name()]; exists {
+		return fmt.Errorf("tool '%s' is already registered", tool.Name())
+	}
+	registry[tool.Name()] = tool
+	return nil
+}
+
+func GetTool(name string) (Tool, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	tool, found := registry[name]
+	return tool, found
+}
+
+// ListTools returns the names of all registered tools in sorted order.
+func ListTools() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
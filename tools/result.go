@@ -0,0 +1,29 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// NormalizeResult converts a tool result into the map carried by a
+// FunctionResponse. A map[string]any is returned unchanged. Any other value is
+// round-tripped through JSON: values that encode to a JSON object, such as
+// typed structs and maps, are returned as that object, and everything else
+// (slices, strings, numbers, booleans, nil) is wrapped as {"result": value}.
+func NormalizeResult(result any) (map[string]any, error) {
+	if m, ok := result.(map[string]any); ok {
+		return m, nil
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("result of type %T is not JSON serializable: %w", result, err)
+	}
+	var decoded any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, fmt.Errorf("result of type %T could not be decoded: %w", result, err)
+	}
+	if m, ok := decoded.(map[string]any); ok {
+		return m, nil
+	}
+	return map[string]any{"result": decoded}, nil
+}
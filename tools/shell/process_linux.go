@@ -0,0 +1,73 @@
+//go:build linux
+
+package shell
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// start launches the command. When limits are set the child is started
+// under ptrace, which stops it right after exec and before it runs any
+// instruction of the new program; the rlimits are applied at that point and
+// the child is then released.
+func start(cmd *exec.Cmd, limits *ResourceLimits) error {
+	if limits == nil {
+		return cmd.Start()
+	}
+
+	// Ptrace requests must come from the thread that started the tracee.
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	cmd.SysProcAttr.Ptrace = true
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	pid := cmd.Process.Pid
+
+	var status syscall.WaitStatus
+	if _, err := syscall.Wait4(pid, &status, syscall.WALL, nil); err != nil {
+		_ = cmd.Process.Kill()
+		return fmt.Errorf("failed to wait for child to stop: %w", err)
+	}
+	if !status.Stopped() {
+		return fmt.Errorf("child exited before resource limits could be applied")
+	}
+	if err := applyLimits(pid, limits); err != nil {
+		_ = cmd.Process.Kill()
+		_ = syscall.PtraceDetach(pid)
+		return fmt.Errorf("failed to apply resource limits: %w", err)
+	}
+	if err := syscall.PtraceDetach(pid); err != nil {
+		_ = cmd.Process.Kill()
+		return fmt.Errorf("failed to release child: %w", err)
+	}
+	return nil
+}
+
+func applyLimits(pid int, limits *ResourceLimits) error {
+	for _, l := range []struct {
+		resource int
+		value    uint64
+	}{
+		{unix.RLIMIT_CPU, limits.CPUSeconds},
+		{unix.RLIMIT_AS, limits.MemoryBytes},
+		{unix.RLIMIT_NPROC, limits.MaxProcesses},
+		{unix.RLIMIT_FSIZE, limits.MaxFileSize},
+		{unix.RLIMIT_NOFILE, limits.MaxOpenFiles},
+	} {
+		if l.value == 0 {
+			continue
+		}
+		rlimit := &unix.Rlimit{Cur: l.value, Max: l.value}
+		if err := unix.Prlimit(pid, l.resource, rlimit, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+
+package shell
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+func start(cmd *exec.Cmd, limits *ResourceLimits) error {
+	if limits != nil {
+		return fmt.Errorf("resource limits are only supported on Linux")
+	}
+	return cmd.Start()
+}
@@ -0,0 +1,320 @@
+package shell
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/KennethanCeyer/adk-go/agents/invocation"
+	"github.com/KennethanCeyer/adk-go/internal/process"
+)
+
+const (
+	defaultTimeout        = 30 * time.Second
+	defaultMaxOutputBytes = 64 << 10 // 64 KiB per stream
+)
+
+// Rule matches a command by executable and, optionally, by its arguments.
+type Rule struct {
+	// Binary is either a bare command name such as "go", or an absolute path
+	// that must match the resolved executable exactly. In an allow rule a
+	// bare name only matches the command when it is looked up through PATH;
+	// in a deny rule it also matches any path ending in that name, so
+	// "/bin/rm" and "./rm" are refused by a rule for "rm".
+	Binary string
+	// Args, when set, must match the whole space-joined argument list, as if
+	// it were wrapped in ^(?:...)$. A deny rule also matches when Args
+	// matches any single argument, so a rule for "-rf" refuses "rm -rf /".
+	Args *regexp.Regexp
+}
+
+func (r Rule) matches(command, resolved string, args []string, deny bool) bool {
+	if !r.matchesBinary(command, resolved, deny) {
+		return false
+	}
+	if r.Args == nil {
+		return true
+	}
+	anchored := regexp.MustCompile("^(?:" + r.Args.String() + ")$")
+	if anchored.MatchString(strings.Join(args, " ")) {
+		return true
+	}
+	if deny {
+		for _, arg := range args {
+			if anchored.MatchString(arg) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (r Rule) matchesBinary(command, resolved string, deny bool) bool {
+	if filepath.IsAbs(r.Binary) {
+		return r.Binary == resolved
+	}
+	if !strings.ContainsRune(command, filepath.Separator) && r.Binary == command {
+		return true
+	}
+	// Commands given as paths never match bare-name allow rules, so a
+	// workspace file called "go" cannot impersonate the real binary.
+	return deny && r.Binary == filepath.Base(resolved)
+}
+
+func (r Rule) String() string {
+	if r.Args == nil {
+		return r.Binary
+	}
+	return fmt.Sprintf("%s /%s/", r.Binary, r.Args.String())
+}
+
+// ResourceLimits are applied to the child process on Linux. Zero values
+// leave the corresponding limit untouched.
+type ResourceLimits struct {
+	CPUSeconds   uint64
+	MemoryBytes  uint64
+	MaxProcesses uint64
+	MaxFileSize  uint64
+	MaxOpenFiles uint64
+}
+
+type Config struct {
+	// WorkDir is the directory commands run in. Required.
+	WorkDir string
+	// Allow, when non-empty, restricts execution to matching commands.
+	Allow []Rule
+	// Deny rejects matching commands even if they are allowed.
+	Deny []Rule
+	// Timeout bounds each command's wall time. Defaults to 30s.
+	Timeout time.Duration
+	// MaxOutputBytes caps the captured size of stdout and stderr each. Defaults to 64 KiB.
+	MaxOutputBytes int
+	// PassEnv names the environment variables inherited from this process.
+	PassEnv []string
+	// Env holds extra KEY=VALUE entries added to the scrubbed environment.
+	Env []string
+	// Limits optionally sets rlimits on the child process (Linux only).
+	Limits *ResourceLimits
+}
+
+// Tool runs allowlisted commands in a fixed working directory. Commands are
+// executed directly, never through a shell, so arguments are not subject to
+// expansion or redirection.
+type Tool struct {
+	cfg Config
+}
+
+func NewTool(cfg Config) (*Tool, error) {
+	if cfg.WorkDir == "" {
+		return nil, fmt.Errorf("shell: WorkDir is required")
+	}
+	workDir, err := filepath.Abs(cfg.WorkDir)
+	if err != nil {
+		return nil, fmt.Errorf("shell: invalid WorkDir '%s': %w", cfg.WorkDir, err)
+	}
+	info, err := os.Stat(workDir)
+	if err != nil {
+		return nil, fmt.Errorf("shell: cannot access WorkDir '%s': %w", cfg.WorkDir, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("shell: WorkDir '%s' is not a directory", cfg.WorkDir)
+	}
+	if len(cfg.Allow) == 0 && len(cfg.Deny) == 0 {
+		return nil, fmt.Errorf("shell: at least one allow or deny rule is required")
+	}
+	cfg.WorkDir = workDir
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.MaxOutputBytes <= 0 {
+		cfg.MaxOutputBytes = defaultMaxOutputBytes
+	}
+	if cfg.PassEnv == nil {
+		cfg.PassEnv = process.PassEnv
+	}
+	return &Tool{cfg: cfg}, nil
+}
+
+func (t *Tool) Name() string { return "run_command" }
+
+func (t *Tool) Description() string {
+	desc := "Runs a command in the workspace and returns its stdout, stderr and exit code. The command is executed directly without a shell, so pipes, redirects and globbing are not available."
+	if len(t.cfg.Allow) > 0 {
+		allowed := make([]string, len(t.cfg.Allow))
+		for i, r := range t.cfg.Allow {
+			allowed[i] = r.String()
+		}
+		desc += " Allowed commands: " + strings.Join(allowed, ", ") + "."
+	}
+	return desc
+}
+
+func (t *Tool) Parameters() any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"command": map[string]any{
+				"type":        "string",
+				"description": "The executable to run, e.g. 'go' or 'git'.",
+			},
+			"args": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string"},
+				"description": "Arguments passed to the command, e.g. ['test', './...'].",
+			},
+		},
+		"required": []string{"command"},
+	}
+}
+
+func (t *Tool) Execute(ctx context.Context, args any) (any, error) {
+	argsMap, ok := args.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("run_command: invalid arguments format, expected map[string]any, got %T", args)
+	}
+	command, ok := argsMap["command"].(string)
+	if !ok || command == "" {
+		return nil, fmt.Errorf("run_command: 'command' is a required argument and must be a string")
+	}
+	var cmdArgs []string
+	if rawArgs, present := argsMap["args"]; present && rawArgs != nil {
+		list, ok := rawArgs.([]any)
+		if !ok {
+			return nil, fmt.Errorf("run_command: 'args' must be an array of strings, got %T", rawArgs)
+		}
+		for _, a := range list {
+			s, ok := a.(string)
+			if !ok {
+				return nil, fmt.Errorf("run_command: 'args' must be an array of strings, got element of type %T", a)
+			}
+			cmdArgs = append(cmdArgs, s)
+		}
+	}
+
+	commandLine := strings.TrimSpace(command + " " + strings.Join(cmdArgs, " "))
+	resolved, err := t.lookPath(command)
+	if err != nil {
+		invocation.SendInternalLog(ctx, "  - Command denied: '%s' (%v)", commandLine, err)
+		return nil, fmt.Errorf("run_command: %w", err)
+	}
+	reason, allowed := t.decide(command, resolved, cmdArgs)
+	if !allowed {
+		invocation.SendInternalLog(ctx, "  - Command denied: '%s' (%s)", commandLine, reason)
+		return nil, fmt.Errorf("run_command: command '%s' is not permitted: %s", commandLine, reason)
+	}
+	invocation.SendInternalLog(ctx, "  - Command allowed: '%s' (%s)", commandLine, reason)
+
+	return t.run(ctx, resolved, cmdArgs)
+}
+
+// decide applies the deny rules first, then the allow rules.
+func (t *Tool) decide(command, resolved string, args []string) (string, bool) {
+	for _, rule := range t.cfg.Deny {
+		if rule.matches(command, resolved, args, true) {
+			return fmt.Sprintf("matched deny rule '%s'", rule), false
+		}
+	}
+	if len(t.cfg.Allow) == 0 {
+		return "no deny rule matched", true
+	}
+	for _, rule := range t.cfg.Allow {
+		if rule.matches(command, resolved, args, false) {
+			return fmt.Sprintf("matched allow rule '%s'", rule), true
+		}
+	}
+	return "no allow rule matched", false
+}
+
+// lookPath resolves the executable using the scrubbed PATH the command will
+// run with, relative paths being taken from the working directory.
+func (t *Tool) lookPath(command string) (string, error) {
+	if strings.ContainsRune(command, filepath.Separator) {
+		p := command
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(t.cfg.WorkDir, p)
+		}
+		info, err := os.Stat(p)
+		if err != nil || info.IsDir() || info.Mode().Perm()&0o111 == 0 {
+			return "", fmt.Errorf("executable '%s' not found", command)
+		}
+		return filepath.Clean(p), nil
+	}
+	for _, dir := range filepath.SplitList(envValue(t.environment(), "PATH")) {
+		if dir == "" || !filepath.IsAbs(dir) {
+			continue
+		}
+		p := filepath.Join(dir, command)
+		if info, err := os.Stat(p); err == nil && !info.IsDir() && info.Mode().Perm()&0o111 != 0 {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("executable '%s' not found in PATH", command)
+}
+
+func (t *Tool) environment() []string {
+	return append(process.Environ(t.cfg.PassEnv), t.cfg.Env...)
+}
+
+func envValue(env []string, name string) string {
+	value := ""
+	for _, kv := range env {
+		if k, v, ok := strings.Cut(kv, "="); ok && k == name {
+			value = v
+		}
+	}
+	return value
+}
+
+func (t *Tool) run(ctx context.Context, executable string, args []string) (any, error) {
+	runCtx, cancel := context.WithTimeout(ctx, t.cfg.Timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(runCtx, executable, args...)
+	cmd.Dir = t.cfg.WorkDir
+	cmd.Env = t.environment()
+	cmd.Stdin = nil
+	stdout := &process.CappedBuffer{Limit: t.cfg.MaxOutputBytes}
+	stderr := &process.CappedBuffer{Limit: t.cfg.MaxOutputBytes}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.WaitDelay = time.Second
+	process.Configure(cmd)
+
+	if err := start(cmd, t.cfg.Limits); err != nil {
+		if cmd.Process != nil {
+			_ = cmd.Wait()
+		}
+		return nil, fmt.Errorf("run_command: failed to start '%s': %w", executable, err)
+	}
+
+	waitErr := cmd.Wait()
+	exitCode := 0
+	if waitErr != nil {
+		var exitErr *exec.ExitError
+		if errors.As(waitErr, &exitErr) {
+			exitCode = exitErr.ExitCode()
+		} else if runCtx.Err() == nil {
+			return nil, fmt.Errorf("run_command: '%s' failed: %w", executable, waitErr)
+		}
+	}
+
+	result := map[string]any{
+		"exit_code": exitCode,
+		"stdout":    stdout.String(),
+		"stderr":    stderr.String(),
+	}
+	if runCtx.Err() == context.DeadlineExceeded {
+		result["timed_out"] = true
+		result["exit_code"] = -1
+	}
+	if stdout.Truncated || stderr.Truncated {
+		result["truncated"] = true
+	}
+	return result, nil
+}
@@ -0,0 +1,71 @@
+package shell
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestDecide(t *testing.T) {
+	gitStatus := Config{Allow: []Rule{{Binary: "git", Args: regexp.MustCompile(`status( --short)?`)}}}
+	noForce := Config{Allow: []Rule{{Binary: "git"}}, Deny: []Rule{{Binary: "git", Args: regexp.MustCompile(`-f|--force`)}}}
+	tests := []struct {
+		name     string
+		cfg      Config
+		command  string
+		resolved string
+		args     []string
+		allowed  bool
+	}{
+		{"deny rule matches bare name", Config{Deny: []Rule{{Binary: "rm"}}}, "rm", "/bin/rm", nil, false},
+		{"deny rule matches absolute path", Config{Deny: []Rule{{Binary: "rm"}}}, "/bin/rm", "/bin/rm", nil, false},
+		{"deny rule matches relative path", Config{Deny: []Rule{{Binary: "rm"}}}, "./rm", "/work/rm", nil, false},
+		{"deny rule leaves other commands", Config{Deny: []Rule{{Binary: "rm"}}}, "/bin/ls", "/bin/ls", nil, true},
+		{"allow rule matches bare name", Config{Allow: []Rule{{Binary: "go"}}}, "go", "/usr/bin/go", nil, true},
+		{"allow rule ignores paths", Config{Allow: []Rule{{Binary: "go"}}}, "./go", "/work/go", nil, false},
+		{"absolute allow rule", Config{Allow: []Rule{{Binary: "/usr/bin/go"}}}, "/usr/bin/go", "/usr/bin/go", nil, true},
+		{"deny wins over allow", Config{Allow: []Rule{{Binary: "rm"}}, Deny: []Rule{{Binary: "rm"}}}, "rm", "/bin/rm", nil, false},
+		{"allow args match", gitStatus, "git", "/usr/bin/git", []string{"status", "--short"}, true},
+		{"allow args trailing command", gitStatus, "git", "/usr/bin/git", []string{"status;", "--exec=sh"}, false},
+		{"allow args extra argument", gitStatus, "git", "/usr/bin/git", []string{"status", "--short", "-c", "core.pager=sh"}, false},
+		{"allow args leading argument", gitStatus, "git", "/usr/bin/git", []string{"-c", "x", "status"}, false},
+		{"allow args partial match", gitStatus, "git", "/usr/bin/git", []string{"statusx"}, false},
+		{"deny args match one argument", noForce, "git", "/usr/bin/git", []string{"push", "--force", "origin"}, false},
+		{"deny args whole argument only", noForce, "git", "/usr/bin/git", []string{"log", "--format=-f"}, true},
+		{"deny args leave other calls", noForce, "git", "/usr/bin/git", []string{"push", "origin"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool := &Tool{cfg: tt.cfg}
+			reason, allowed := tool.decide(tt.command, tt.resolved, tt.args)
+			if allowed != tt.allowed {
+				t.Errorf("decide(%q, %q) = %v (%s), want %v", tt.command, tt.args, allowed, reason, tt.allowed)
+			}
+		})
+	}
+}
+
+func TestExecuteRefusesDeniedPath(t *testing.T) {
+	if _, err := os.Stat("/bin/rm"); err != nil {
+		t.Skip("/bin/rm not available")
+	}
+	workDir := t.TempDir()
+	victim := filepath.Join(workDir, "victim.txt")
+	if err := os.WriteFile(victim, []byte("keep"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tool, err := NewTool(Config{WorkDir: workDir, Deny: []Rule{{Binary: "rm"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = tool.Execute(context.Background(), map[string]any{"command": "/bin/rm", "args": []any{victim}})
+	if err == nil || !strings.Contains(err.Error(), "not permitted") {
+		t.Errorf("err = %v, want the command to be refused", err)
+	}
+	if _, err := os.Stat(victim); err != nil {
+		t.Errorf("file was removed: %v", err)
+	}
+}
@@ -0,0 +1,52 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// Toolset provides tools that are resolved for each model request, so that
+// the available tools can depend on the invocation, such as session state,
+// user permissions or feature flags.
+type Toolset interface {
+	GetTools(ctx context.Context) ([]Tool, error)
+}
+
+// ToolsetFunc adapts a function to the Toolset interface.
+type ToolsetFunc func(ctx context.Context) ([]Tool, error)
+
+func (f ToolsetFunc) GetTools(ctx context.Context) ([]Tool, error) { return f(ctx) }
+
+// Resolve combines static tools with the tools of each toolset for ctx. The
+// result is sorted by name so that requests to providers are stable, and
+// duplicate names are rejected.
+func Resolve(ctx context.Context, static []Tool, toolsets []Toolset) ([]Tool, error) {
+	resolved := make([]Tool, 0, len(static))
+	for _, t := range static {
+		if t != nil {
+			resolved = append(resolved, t)
+		}
+	}
+	for _, ts := range toolsets {
+		if ts == nil {
+			continue
+		}
+		tsTools, err := ts.GetTools(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve toolset: %w", err)
+		}
+		for _, t := range tsTools {
+			if t != nil {
+				resolved = append(resolved, t)
+			}
+		}
+	}
+	sort.SliceStable(resolved, func(i, j int) bool { return resolved[i].Name() < resolved[j].Name() })
+	for i := 1; i < len(resolved); i++ {
+		if resolved[i].Name() == resolved[i-1].Name() {
+			return nil, fmt.Errorf("duplicate tool name '%s'", resolved[i].Name())
+		}
+	}
+	return resolved, nil
+}
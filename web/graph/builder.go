@@ -9,7 +9,7 @@ import (
 )
 
 // Build generates a DOT language string to represent the agent hierarchy.
-func Build(agent interfaces.LlmAgent) string {
+func Build(agent interfaces.Agent) string {
 	var sb strings.Builder
 	sb.WriteString("digraph G {\n")
 	sb.WriteString("  rankdir=TB;\n")
@@ -23,22 +23,26 @@ func Build(agent interfaces.LlmAgent) string {
 	return sb.String()
 }
 
-func buildNode(sb *strings.Builder, agent interfaces.LlmAgent) {
-	agentID := sanitizeID(agent.GetName())
+func buildNode(sb *strings.Builder, agent interfaces.Agent) {
+	agentID := dotID(agent.GetName())
 
 	switch a := agent.(type) {
 	case *agents.BaseLlmAgent:
-		sb.WriteString(fmt.Sprintf("  %s [label=\"%s\\n(LLM Agent)\", fillcolor=\"#e0eafc\"];\n", agentID, agent.GetName()))
+		sb.WriteString(fmt.Sprintf("  %s [label=\"%s\\n(LLM Agent)\", fillcolor=\"#e0eafc\"];\n", agentID, dotLabel(agent.GetName())))
 		for _, tool := range a.GetTools() {
-			toolID := sanitizeID(tool.Name())
-			sb.WriteString(fmt.Sprintf("  %s [label=\"%s\\n(Tool)\", shape=cylinder, fillcolor=\"#fff3cd\"];\n", toolID, tool.Name()))
+			toolID := dotID("tool:" + tool.Name())
+			sb.WriteString(fmt.Sprintf("  %s [label=\"%s\\n(Tool)\", shape=cylinder, fillcolor=\"#fff3cd\"];\n", toolID, dotLabel(tool.Name())))
 			sb.WriteString(fmt.Sprintf("  %s -> %s;\n", agentID, toolID))
 		}
+		for _, subAgent := range a.GetSubAgents() {
+			buildNode(sb, subAgent)
+			sb.WriteString(fmt.Sprintf("  %s -> %s [label=\"transfer\", style=dashed];\n", agentID, dotID(subAgent.GetName())))
+		}
 	case *agents.SequentialAgent:
-		sb.WriteString(fmt.Sprintf("  %s [label=\"%s\\n(Sequential Workflow)\", fillcolor=\"#d1e7dd\"];\n", agentID, agent.GetName()))
+		sb.WriteString(fmt.Sprintf("  %s [label=\"%s\\n(Sequential Workflow)\", fillcolor=\"#d1e7dd\"];\n", agentID, dotLabel(agent.GetName())))
 		var prevSubAgentID string
 		for i, subAgent := range a.SubAgents {
-			subAgentID := sanitizeID(subAgent.GetName())
+			subAgentID := dotID(subAgent.GetName())
 			buildNode(sb, subAgent)
 			if i == 0 {
 				sb.WriteString(fmt.Sprintf("  %s -> %s [label=\"start\"];\n", agentID, subAgentID))
@@ -48,18 +52,75 @@ func buildNode(sb *strings.Builder, agent interfaces.LlmAgent) {
 			prevSubAgentID = subAgentID
 		}
 	case *agents.ParallelAgent:
-		sb.WriteString(fmt.Sprintf("  %s [label=\"%s\\n(Parallel Workflow)\", fillcolor=\"#d1e7dd\"];\n", agentID, agent.GetName()))
+		sb.WriteString(fmt.Sprintf("  %s [label=\"%s\\n(Parallel Workflow)\", fillcolor=\"#d1e7dd\"];\n", agentID, dotLabel(agent.GetName())))
 		for _, subAgent := range a.SubAgents {
-			subAgentID := sanitizeID(subAgent.GetName())
+			subAgentID := dotID(subAgent.GetName())
 			buildNode(sb, subAgent)
 			sb.WriteString(fmt.Sprintf("  %s -> %s [label=\"concurrent\"];\n", agentID, subAgentID))
 		}
+	case *agents.SwitchAgent:
+		sb.WriteString(fmt.Sprintf("  %s [label=\"%s\\n(Switch Workflow)\", shape=diamond, fillcolor=\"#d1e7dd\"];\n", agentID, dotLabel(agent.GetName())))
+		for i, branch := range a.Branches {
+			subAgentID := dotID(branch.Agent.GetName())
+			buildNode(sb, branch.Agent)
+			label := a.BranchLabel(i)
+			if branch.When == nil {
+				label += " (classifier)"
+			}
+			sb.WriteString(fmt.Sprintf("  %s -> %s [label=\"%s\"];\n", agentID, subAgentID, dotLabel(label)))
+		}
+		if a.Default != nil {
+			defaultID := dotID(a.Default.GetName())
+			buildNode(sb, a.Default)
+			sb.WriteString(fmt.Sprintf("  %s -> %s [label=\"default\", style=dashed];\n", agentID, defaultID))
+		}
+	case *agents.GraphAgent:
+		sb.WriteString(fmt.Sprintf("  %s [label=\"%s\\n(Graph Workflow)\", fillcolor=\"#d1e7dd\"];\n", agentID, dotLabel(agent.GetName())))
+		// Dependency edges are labeled with the state key the output travels in.
+		outputKeys := make(map[string]string, len(a.Nodes))
+		for _, node := range a.Nodes {
+			outputKeys[node.Agent.GetName()] = node.Agent.GetName()
+			if node.OutputKey != "" {
+				outputKeys[node.Agent.GetName()] = node.OutputKey
+			}
+		}
+		for _, node := range a.Nodes {
+			nodeID := dotID(node.Agent.GetName())
+			buildNode(sb, node.Agent)
+			if len(node.DependsOn) == 0 {
+				sb.WriteString(fmt.Sprintf("  %s -> %s [label=\"start\"];\n", agentID, nodeID))
+			}
+			for _, dep := range node.DependsOn {
+				sb.WriteString(fmt.Sprintf("  %s -> %s [label=\"%s\"];\n", dotID(dep), nodeID, dotLabel(outputKeys[dep])))
+			}
+		}
+	case *agents.ReflectionAgent:
+		sb.WriteString(fmt.Sprintf("  %s [label=\"%s\\n(Reflection Workflow)\", fillcolor=\"#d1e7dd\"];\n", agentID, dotLabel(agent.GetName())))
+		if a.Generator != nil && a.Critic != nil {
+			generatorID, criticID := dotID(a.Generator.GetName()), dotID(a.Critic.GetName())
+			buildNode(sb, a.Generator)
+			buildNode(sb, a.Critic)
+			sb.WriteString(fmt.Sprintf("  %s -> %s [label=\"start\"];\n", agentID, generatorID))
+			sb.WriteString(fmt.Sprintf("  %s -> %s [label=\"draft\"];\n", generatorID, criticID))
+			sb.WriteString(fmt.Sprintf("  %s -> %s [label=\"revise\", style=dashed, constraint=false];\n", criticID, generatorID))
+		}
+	case *agents.MapAgent:
+		sb.WriteString(fmt.Sprintf("  %s [label=\"%s\\n(Map Workflow)\", fillcolor=\"#d1e7dd\"];\n", agentID, dotLabel(agent.GetName())))
+		if a.Mapper != nil {
+			mapperID := dotID(a.Mapper.GetName())
+			buildNode(sb, a.Mapper)
+			sb.WriteString(fmt.Sprintf("  %s -> %s [label=\"each item\"];\n", agentID, mapperID))
+			if a.ReducerAgent != nil {
+				buildNode(sb, a.ReducerAgent)
+				sb.WriteString(fmt.Sprintf("  %s -> %s [label=\"reduce\"];\n", mapperID, dotID(a.ReducerAgent.GetName())))
+			}
+		}
 	case *agents.LoopAgent:
-		sb.WriteString(fmt.Sprintf("  %s [label=\"%s\\n(Loop Workflow)\", fillcolor=\"#d1e7dd\"];\n", agentID, agent.GetName()))
+		sb.WriteString(fmt.Sprintf("  %s [label=\"%s\\n(Loop Workflow)\", fillcolor=\"#d1e7dd\"];\n", agentID, dotLabel(agent.GetName())))
 		var prevSubAgentID string
 		var firstSubAgentID string
 		for i, subAgent := range a.SubAgents {
-			subAgentID := sanitizeID(subAgent.GetName())
+			subAgentID := dotID(subAgent.GetName())
 			buildNode(sb, subAgent)
 			if i == 0 {
 				firstSubAgentID = subAgentID
@@ -72,10 +133,31 @@ func buildNode(sb *strings.Builder, agent interfaces.LlmAgent) {
 		if prevSubAgentID != "" && firstSubAgentID != "" {
 			sb.WriteString(fmt.Sprintf("  %s -> %s [label=\"repeat\", style=dashed, constraint=false];\n", prevSubAgentID, firstSubAgentID))
 		}
+	case *agents.FuncAgent:
+		sb.WriteString(fmt.Sprintf("  %s [label=\"%s\\n(Function)\", shape=component, fillcolor=\"#f0f0f0\"];\n", agentID, dotLabel(agent.GetName())))
+	default:
+		// Agents this builder does not know are drawn with their sub-agents, if any.
+		sb.WriteString(fmt.Sprintf("  %s [label=\"%s\\n(Agent)\"];\n", agentID, dotLabel(agent.GetName())))
+		if parent, ok := agent.(interfaces.ParentAgent); ok {
+			for _, subAgent := range parent.GetSubAgents() {
+				buildNode(sb, subAgent)
+				sb.WriteString(fmt.Sprintf("  %s -> %s;\n", agentID, dotID(subAgent.GetName())))
+			}
+		}
 	}
 }
 
-func sanitizeID(name string) string {
-	r := strings.NewReplacer(" ", "_", "-", "_", ".", "_")
-	return r.Replace(name)
+// dotEscaper escapes text for use inside a quoted DOT string.
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// dotID quotes name for use as a DOT node ID, so that every distinct agent
+// name gets its own node. Tool IDs are prefixed to keep them apart from
+// agents of the same name.
+func dotID(name string) string {
+	return `"` + dotEscaper.Replace(name) + `"`
+}
+
+// dotLabel escapes text for use inside a quoted label.
+func dotLabel(text string) string {
+	return dotEscaper.Replace(text)
 }
@@ -0,0 +1,19 @@
+package graph
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/KennethanCeyer/adk-go/agents"
+)
+
+func TestBuildEscapesLabels(t *testing.T) {
+	step := agents.NewFuncAgent(`say "hi" \ bye`, "", agents.TextFunc(func(_ context.Context, text string) (string, error) {
+		return text, nil
+	}))
+	dot := Build(step)
+	if !strings.Contains(dot, `[label="say \"hi\" \\ bye\n(Function)"`) {
+		t.Errorf("label is not escaped:\n%s", dot)
+	}
+}
@@ -2,14 +2,18 @@ package web
 
 import (
 	"context"
+	"encoding/json"
 	"log"
 	"net/http"
 	"sync"
 
+	"github.com/KennethanCeyer/adk-go/adk"
+	"github.com/KennethanCeyer/adk-go/agents"
 	"github.com/KennethanCeyer/adk-go/agents/interfaces"
 	"github.com/KennethanCeyer/adk-go/agents/invocation"
 	modelstypes "github.com/KennethanCeyer/adk-go/models/types"
 	"github.com/KennethanCeyer/adk-go/sessions"
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 )
 
@@ -20,6 +24,11 @@ type UIMessage struct {
 
 const maxHistoryTurns = 20 // Limit conversation history to the last 20 turns (40 messages)
 
+// maxQueuedMessages is how many messages a client may send ahead of the turn
+// that is running. Further messages are rejected rather than blocking the
+// read loop, which must stay free to receive tool confirmations.
+const maxQueuedMessages = 32
+
 var upgrader = websocket.Upgrader{
 	ReadBufferSize:  1024,
 	WriteBufferSize: 1024,
@@ -32,18 +41,34 @@ var upgrader = websocket.Upgrader{
 
 // WebSocketHandler handles WebSocket connections.
 type WebSocketHandler struct {
-	agent interfaces.LlmAgent
+	// RunConfig limits each turn of the conversation; nil means no limits.
+	RunConfig *invocation.RunConfig
+
+	agent interfaces.Agent
 	sess  *sessions.Session
 	conn  *websocket.Conn
 	mu    sync.Mutex // Protects concurrent writes to the WebSocket connection
+
+	pendingMu sync.Mutex
+	pending   map[string]chan invocation.ToolConfirmation // Tool confirmations awaiting an answer, by request ID
+}
+
+// toolConfirmationResponse is sent by the UI to answer a tool_confirmation_request.
+type toolConfirmationResponse struct {
+	Type    string `json:"type"`
+	Payload struct {
+		ID       string `json:"id"`
+		Approved bool   `json:"approved"`
+		Reason   string `json:"reason"`
+	} `json:"payload"`
 }
 
 // NewWebSocketHandler creates a new WebSocketHandler.
-func NewWebSocketHandler(agent interfaces.LlmAgent, sess *sessions.Session) *WebSocketHandler {
+func NewWebSocketHandler(agent interfaces.Agent, sess *sessions.Session) *WebSocketHandler {
 	if sess.State == nil {
 		sess.State = make(map[string]any)
 	}
-	return &WebSocketHandler{agent: agent, sess: sess}
+	return &WebSocketHandler{agent: agent, sess: sess, pending: make(map[string]chan invocation.ToolConfirmation)}
 }
 
 // ServeWS handles WebSocket requests from the peer.
@@ -62,6 +87,27 @@ func (h *WebSocketHandler) ServeWS(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Turns run on a single worker outside the read loop, in the order the
+	// messages arrived, so that tool confirmations can be read while the
+	// agent is waiting for them.
+	ctx, cancel := context.WithCancel(r.Context())
+	incoming := make(chan []byte, maxQueuedMessages)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for msgBytes := range incoming {
+			if ctx.Err() != nil {
+				continue // The client is gone; drop what it queued.
+			}
+			h.handleIncomingMessage(ctx, msgBytes)
+		}
+	}()
+	defer func() {
+		cancel()
+		close(incoming)
+		<-done
+	}()
+
 	for {
 		_, p, err := conn.ReadMessage()
 		if err != nil {
@@ -71,7 +117,15 @@ func (h *WebSocketHandler) ServeWS(w http.ResponseWriter, r *http.Request) {
 			break
 		}
 
-		h.handleIncomingMessage(r.Context(), p)
+		if h.resolveConfirmation(p) {
+			continue
+		}
+		select {
+		case incoming <- p:
+		default:
+			errorText := "Too many messages are waiting for an answer; please wait for the agent to reply."
+			_ = h.sendJSON("agent_response", modelstypes.Message{Role: "model", Parts: []modelstypes.Part{{Text: &errorText}}})
+		}
 	}
 	log.Println("Client disconnected.")
 }
@@ -85,10 +139,14 @@ func (h *WebSocketHandler) sendJSON(messageType string, payload any) error {
 }
 
 func (h *WebSocketHandler) sendInitialState() error {
+	agentType := agents.TypeName(h.agent)
+	if llmAgent, ok := h.agent.(interfaces.LlmAgent); ok {
+		agentType = llmAgent.GetModelIdentifier()
+	}
 	infoPayload := map[string]string{
 		"agentName":        h.agent.GetName(),
 		"agentDescription": h.agent.GetDescription(),
-		"agentType":        h.agent.GetModelIdentifier(),
+		"agentType":        agentType,
 		"sessionId":        h.sess.ID,
 	}
 	if err := h.sendJSON("system_info", infoPayload); err != nil {
@@ -105,6 +163,7 @@ func (h *WebSocketHandler) sendInitialState() error {
 
 func (h *WebSocketHandler) handleIncomingMessage(ctx context.Context, msgBytes []byte) {
 	log.Printf("Received from client: %s", msgBytes)
+	defer h.sess.BeginTurn()()
 	userInputText := string(msgBytes)
 	userMessage := modelstypes.Message{Role: "user", Parts: []modelstypes.Part{{Text: &userInputText}}}
 
@@ -123,10 +182,24 @@ func (h *WebSocketHandler) handleIncomingMessage(ctx context.Context, msgBytes [
 	uiSender := func(messageType string, payload any) {
 		_ = h.sendJSON(messageType, payload)
 	}
+	agent := adk.ActiveAgent(h.agent, h.sess)
+	invCtx := &invocation.InvocationContext{
+		ID:        uuid.NewString(),
+		Agent:     agent,
+		Session:   h.sess,
+		RunConfig: h.RunConfig,
+	}
 	agentCtx := invocation.WithUISender(ctx, uiSender)
+	agentCtx = invocation.WithConfirmer(agentCtx, h.confirmTool)
+	agentCtx, cancel := invocation.Start(agentCtx, invCtx)
+	defer cancel()
 
-	// Process message with the agent.
-	response, err := h.agent.Process(agentCtx, h.sess.History, userMessage)
+	// Process message with the agent that currently owns the session.
+	response, err := agent.Process(agentCtx, h.sess.History, userMessage)
+	err = invCtx.BudgetErr(err, agent.GetName())
+	if transferredTo := invCtx.TransferredTo(); transferredTo != "" {
+		h.sess.ActiveAgent = transferredTo
+	}
 	if err != nil {
 		log.Printf("Agent processing error: %v", err)
 		errorText := "I encountered an error: " + err.Error()
@@ -158,3 +231,45 @@ func (h *WebSocketHandler) handleIncomingMessage(ctx context.Context, msgBytes [
 	// Send state update to client
 	_ = h.sendJSON("state_update", h.sess.State)
 }
+
+// confirmTool waits for the UI to answer the tool_confirmation_request the
+// agent has sent for req.
+func (h *WebSocketHandler) confirmTool(ctx context.Context, req invocation.ToolConfirmationRequest) (invocation.ToolConfirmation, error) {
+	answer := make(chan invocation.ToolConfirmation, 1)
+	h.pendingMu.Lock()
+	h.pending[req.ID] = answer
+	h.pendingMu.Unlock()
+	defer func() {
+		h.pendingMu.Lock()
+		delete(h.pending, req.ID)
+		h.pendingMu.Unlock()
+	}()
+
+	select {
+	case confirmation := <-answer:
+		return confirmation, nil
+	case <-ctx.Done():
+		return invocation.ToolConfirmation{}, ctx.Err()
+	}
+}
+
+// resolveConfirmation handles msgBytes if it is a tool confirmation response,
+// reporting whether it was one.
+func (h *WebSocketHandler) resolveConfirmation(msgBytes []byte) bool {
+	var resp toolConfirmationResponse
+	if err := json.Unmarshal(msgBytes, &resp); err != nil || resp.Type != "tool_confirmation_response" {
+		return false
+	}
+	h.pendingMu.Lock()
+	answer, ok := h.pending[resp.Payload.ID]
+	h.pendingMu.Unlock()
+	if !ok {
+		log.Printf("Ignoring confirmation for unknown request '%s'", resp.Payload.ID)
+		return true
+	}
+	select {
+	case answer <- invocation.ToolConfirmation{Approved: resp.Payload.Approved, Reason: resp.Payload.Reason}:
+	default: // Already answered.
+	}
+	return true
+}
@@ -8,6 +8,9 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/KennethanCeyer/adk-go/agents"
+	"github.com/KennethanCeyer/adk-go/agents/interfaces"
+	"github.com/KennethanCeyer/adk-go/agents/invocation"
 	"github.com/KennethanCeyer/adk-go/examples"
 	"github.com/KennethanCeyer/adk-go/sessions"
 	"github.com/KennethanCeyer/adk-go/web/graph"
@@ -16,15 +19,16 @@ import (
 //go:embed index.html
 var indexHTML []byte
 
-// StartServer initializes and starts the web server.
-func StartServer(addr string) {
+// StartServer initializes and starts the web server. Every turn of every
+// conversation is limited by config, which may be nil.
+func StartServer(addr string, config *invocation.RunConfig) {
 	// Serve static files from the "static" directory.
 	// This allows serving CSS, JS, images, fonts, etc.
 	fs := http.FileServer(http.Dir("web/static"))
 	http.Handle("/static/", http.StripPrefix("/static/", fs))
 
 	// The WebSocket handler is now created per-connection, based on the requested agent.
-	http.HandleFunc("/ws", serveWS)
+	http.HandleFunc("/ws", serveWS(config))
 
 	http.HandleFunc("/api/sessions", handleListSessions)
 	http.HandleFunc("/api/session/", handleSession) // Note the trailing slash
@@ -152,8 +156,9 @@ func handleDetails(w http.ResponseWriter, r *http.Request) {
 		Type        string `json:"type"`
 	}
 
-	if toolName != "" {
-		for _, tool := range agent.GetTools() {
+	llmAgent, isLlm := agent.(interfaces.LlmAgent)
+	if toolName != "" && isLlm {
+		for _, tool := range llmAgent.GetTools() {
 			if tool.Name() == toolName {
 				details.Name = tool.Name()
 				details.Description = tool.Description()
@@ -164,7 +169,10 @@ func handleDetails(w http.ResponseWriter, r *http.Request) {
 	} else {
 		details.Name = agent.GetName()
 		details.Description = agent.GetDescription()
-		details.Type = agent.GetModelIdentifier()
+		details.Type = agents.TypeName(agent)
+		if isLlm {
+			details.Type = llmAgent.GetModelIdentifier()
+		}
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -189,26 +197,30 @@ func handleAgentGraph(w http.ResponseWriter, r *http.Request) {
 	_, _ = w.Write([]byte(dotSource))
 }
 
-// serveWS handles WebSocket requests from the peer.
-func serveWS(w http.ResponseWriter, r *http.Request) {
-	query := r.URL.Query()
-	agentName := query.Get("agent")
-	sessionID := query.Get("sessionId")
+// serveWS returns the handler for WebSocket requests from the peer. Turns
+// run by the handler are limited by config.
+func serveWS(config *invocation.RunConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		agentName := query.Get("agent")
+		sessionID := query.Get("sessionId")
 
-	if agentName == "" { http.Error(w, "Missing 'agent' query parameter", http.StatusBadRequest); return }
+		if agentName == "" { http.Error(w, "Missing 'agent' query parameter", http.StatusBadRequest); return }
 
-	agent, found := examples.GetAgent(agentName)
-	if !found || agent == nil {
-		log.Printf("WebSocket connection request for unknown or uninitialized agent: %s", agentName)
-		http.Error(w, fmt.Sprintf("Agent '%s' not found or not initialized", agentName), http.StatusNotFound)
-		return
-	}
+		agent, found := examples.GetAgent(agentName)
+		if !found || agent == nil {
+			log.Printf("WebSocket connection request for unknown or uninitialized agent: %s", agentName)
+			http.Error(w, fmt.Sprintf("Agent '%s' not found or not initialized", agentName), http.StatusNotFound)
+			return
+		}
 
-	// Get an existing session for the agent, or create a new one.
-	// This allows users to continue conversations by using the same session ID.
-	currentSession := sessions.GetOrCreate(agentName, sessionID)
-	log.Printf("WebSocket connected for agent '%s' with session ID '%s'", agentName, currentSession.ID)
+		// Get an existing session for the agent, or create a new one.
+		// This allows users to continue conversations by using the same session ID.
+		currentSession := sessions.GetOrCreate(agentName, sessionID)
+		log.Printf("WebSocket connected for agent '%s' with session ID '%s'", agentName, currentSession.ID)
 
-	handler := NewWebSocketHandler(agent, currentSession)
-	handler.ServeWS(w, r)
+		handler := NewWebSocketHandler(agent, currentSession)
+		handler.RunConfig = config
+		handler.ServeWS(w, r)
+	}
 }